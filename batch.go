@@ -0,0 +1,367 @@
+package goadawasm
+
+import (
+	"math"
+	"runtime"
+	"slices"
+
+	"github.com/tetratelabs/wazero/api"
+)
+
+// BatchResult is the outcome of parsing one input of a batch
+type BatchResult struct {
+	Url *Url  // The parsed URL, nil on failure
+	Err error // A *ParseError if the input could not be parsed
+}
+
+// ParseBatch parses the given strings into URLs, in order.
+// It copies the inputs into WASM memory once and holds a single parser for the whole batch,
+// which makes it faster than calling New for each input;
+// an input that makes the instance fail reports ErrEngineFailure and the others are parsed on a fresh instance.
+// Every returned Url must be freed.
+func ParseBatch(inputs []string) []BatchResult {
+	return defaultEngine().ParseBatch(inputs)
+}
+
+// ParseBatchWithBase parses the given strings into URLs against the base, in order.
+// See ParseBatch.
+func ParseBatchWithBase(inputs []string, basestring string) []BatchResult {
+	return defaultEngine().ParseBatchWithBase(inputs, basestring)
+}
+
+// ParseBatch parses the given strings into URLs using a single parser of the engine,
+// and a fresh one for the other inputs once an input made the instance fail
+func (e *Engine) ParseBatch(inputs []string) []BatchResult {
+	return e.parseBatch(inputs, "parse", "", func(p *Parser, inputs []string) []BatchResult {
+		return p.parseBatch(inputs)
+	})
+}
+
+// ParseBatchWithBase parses the given strings into URLs against the base using a single parser of the engine,
+// and a fresh one for the other inputs once an input made the instance fail
+func (e *Engine) ParseBatchWithBase(inputs []string, basestring string) []BatchResult {
+	return e.parseBatch(inputs, "parse with base", basestring, func(p *Parser, inputs []string) []BatchResult {
+		return p.parseBatchWithBase(inputs, basestring)
+	})
+}
+
+// parseBatch runs parse on a parser of the engine until every input has a result.
+// parse returns the results of a prefix of the inputs, holding at least one of them.
+// The URLs of a run whose instance failed, or failed later, are parsed again on the next run,
+// so that no returned URL lives in a discarded instance.
+func (e *Engine) parseBatch(inputs []string, op, base string, parse func(*Parser, []string) []BatchResult) []BatchResult {
+	results := make([]BatchResult, len(inputs))
+	pending := make([]int, len(inputs))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for len(pending) > 0 {
+		rest := make([]string, len(pending))
+		for j, i := range pending {
+			rest[j] = inputs[i]
+		}
+		parser, err := e.acquireParser()
+		if err != nil {
+			for j, result := range failBatch(rest, op, base, ReasonEngineFailure, err) {
+				results[pending[j]] = result
+			}
+			break
+		}
+		run := parse(parser, rest)
+		e.releaseParser(parser)
+		for j, result := range run {
+			results[pending[j]] = result
+		}
+
+		// The input a failed instance stopped at reports the failure, unlike the URLs that lived in it
+		next := pending[len(run):]
+		pending = nil
+		for i, result := range results {
+			if result.Url != nil && result.Url.Err() != nil {
+				result.Url.Free()
+				results[i] = BatchResult{}
+				pending = append(pending, i)
+			}
+		}
+		pending = append(pending, next...)
+		slices.Sort(pending)
+	}
+	return results
+}
+
+// ParseBatch parses the given strings into URLs using the parser.
+// Failures are reported per input as a *ParseError; the inputs after one that made the instance fail
+// report ErrEngineFailure without being parsed.
+func (p *Parser) ParseBatch(inputs []string) []BatchResult {
+	return p.finishBatch(inputs, "parse", "", p.parseBatch(inputs))
+}
+
+// ParseBatchWithBase parses the given strings into URLs against the base using the parser.
+// Failures are reported per input as a *ParseError; an invalid base fails every input.
+// As with ParseBatch, the inputs after one that made the instance fail are not parsed.
+func (p *Parser) ParseBatchWithBase(inputs []string, basestring string) []BatchResult {
+	return p.finishBatch(inputs, "parse with base", basestring, p.parseBatchWithBase(inputs, basestring))
+}
+
+// finishBatch fails the inputs a batch stopped before
+func (p *Parser) finishBatch(inputs []string, op, base string, results []BatchResult) []BatchResult {
+	if len(results) == len(inputs) {
+		return results
+	}
+	return append(results, failBatch(inputs[len(results):], op, base, ReasonEngineFailure, p.err())...)
+}
+
+// parseBatch parses the given strings into URLs, stopping after an input that made the instance fail
+func (p *Parser) parseBatch(inputs []string) []BatchResult {
+	results := make([]BatchResult, 0, len(inputs))
+
+	offsets, err := p.writeBatch(inputs)
+	if err != nil {
+		return failBatch(inputs, "parse", "", ReasonEngineFailure, err)
+	}
+	parseFunc := p.getFunction("ada_parse")
+	if parseFunc == nil {
+		return failBatch(inputs, "parse", "", ReasonEngineFailure, engineFailure("ada_parse function not found"))
+	}
+	flag := p.validFlag()
+	urls := make([]Url, len(inputs))
+
+	for i, input := range inputs {
+		if len(input) == 0 {
+			results = append(results, BatchResult{Err: newParseError("parse", input, "", ReasonEmptyInput, ErrEmptyString)})
+			continue
+		}
+		results = append(results, p.parseBatchItem(&urls[i], "parse", input, "", flag, parseFunc, uint64(offsets[i]), uint64(len(input))))
+		if p.discarded.Load() {
+			break
+		}
+	}
+	addBatchCleanup(urls, results)
+	return results
+}
+
+// parseBatchWithBase parses the given strings into URLs against the base,
+// stopping after an input that made the instance fail
+func (p *Parser) parseBatchWithBase(inputs []string, basestring string) []BatchResult {
+	const op = "parse with base"
+	results := make([]BatchResult, 0, len(inputs))
+
+	// The base is written behind the inputs, so it is copied into WASM memory only once
+	offsets, err := p.writeBatch(append(inputs[:len(inputs):len(inputs)], basestring))
+	if err != nil {
+		return failBatch(inputs, op, basestring, ReasonEngineFailure, err)
+	}
+	basePtr := offsets[len(inputs)]
+
+	parseFunc := p.getFunction("ada_parse_with_base")
+	if parseFunc == nil {
+		return failBatch(inputs, op, basestring, ReasonEngineFailure, engineFailure("ada_parse_with_base function not found"))
+	}
+	canParseFunc := p.getFunction("ada_can_parse")
+	if canParseFunc == nil {
+		return failBatch(inputs, op, basestring, ReasonEngineFailure, engineFailure("ada_can_parse function not found"))
+	}
+	canParse, err := p.call(canParseFunc, uint64(basePtr), uint64(len(basestring)))
+	if err != nil {
+		return failBatch(inputs, op, basestring, ReasonEngineFailure, err)
+	}
+	flag := p.validFlag()
+	if canParse[0] == 0 {
+		// No input resolves against an invalid base, not even an absolute one
+		for _, input := range inputs {
+			reason, err := ReasonInvalidBase, ErrInvalidUrl
+			if len(input) == 0 && len(basestring) == 0 {
+				reason, err = ReasonEmptyInput, ErrEmptyString
+			}
+			results = append(results, BatchResult{Err: newParseError(op, input, basestring, reason, err)})
+		}
+		return results
+	}
+
+	urls := make([]Url, len(inputs))
+
+	for i, input := range inputs {
+		results = append(results, p.parseBatchItem(&urls[i], op, input, basestring, flag, parseFunc,
+			uint64(offsets[i]), uint64(len(input)), uint64(basePtr), uint64(len(basestring))))
+		if p.discarded.Load() {
+			break
+		}
+	}
+	addBatchCleanup(urls, results)
+	return results
+}
+
+// parseBatchItem parses one input of a batch already written to WASM memory into url,
+// reading the validity of the result at the offset flag if it is positive
+func (p *Parser) parseBatchItem(url *Url, op, input, base string, flag int32, parseFunc api.Function, args ...uint64) BatchResult {
+	fail := func(reason string, err error) BatchResult {
+		return BatchResult{Err: newParseError(op, input, base, reason, err)}
+	}
+
+	results, err := p.call(parseFunc, args...)
+	if err != nil {
+		return fail(ReasonEngineFailure, err)
+	}
+
+	urlObjPtr := uint32(results[0])
+	if urlObjPtr == 0 {
+		return fail(ReasonEngineFailure, engineFailure("empty url object!"))
+	}
+
+	var valid bool
+	if flag > 0 {
+		b, ok := p.module.Memory().ReadByte(urlObjPtr + uint32(flag))
+		valid = ok && b != 0
+	} else {
+		valid = p.callAdaBoolFunction("ada_is_valid", urlObjPtr)
+	}
+	if !valid {
+		p.freeUrlObject(urlObjPtr)
+		return fail(ReasonInvalidInput, ErrInvalidUrl)
+	}
+
+	url.object = p.addObject(urlObjPtr, false)
+	return BatchResult{Url: url}
+}
+
+// addBatchCleanup frees the objects of the URLs of a batch that were not freed once all of them are
+// collected. A single cleanup for the whole batch is much cheaper than one per URL, which the runtime
+// keeps in a list per memory span, while freed URLs release their objects right away all the same.
+func addBatchCleanup(urls []Url, results []BatchResult) {
+	var objects []*object
+	for _, result := range results {
+		if result.Url != nil {
+			objects = append(objects, result.Url.object)
+		}
+	}
+	if len(objects) > 0 {
+		runtime.AddCleanup(&urls[0], freeUrlObjects, objects)
+	}
+}
+
+// freeUrlObjects frees the objects of the URLs of a batch
+func freeUrlObjects(objects []*object) {
+	for _, o := range objects {
+		o.freeUrl()
+	}
+}
+
+// validFlag returns the offset of the flag telling whether an ada_url object holds a URL, so that a batch
+// reads the validity of each result instead of calling ada_is_valid for it.
+// It is -1 if the flag was not found, and 0 if the instance failed before it was.
+func (p *Parser) validFlag() int32 {
+	if flag := p.engine.validFlag.Load(); flag != 0 {
+		return flag
+	}
+	flag := p.findValidFlag()
+	if flag != 0 {
+		p.engine.validFlag.Store(flag)
+	}
+	return flag
+}
+
+// findValidFlag locates the validity flag by parsing a valid and an invalid URL. ada_url points to a
+// tl::expected<url_aggregator>, whose has-value flag follows the ada_url_components ending the url_aggregator.
+// The offset is only trusted if the flag agrees with ada_is_valid for both.
+func (p *Parser) findValidFlag() int32 {
+	const componentsSize = 32 // 8 uint32 fields
+
+	validPtr, err := p.parseObject("https://example.com/")
+	if err != nil {
+		return 0
+	}
+	defer p.freeUrlObject(validPtr)
+	invalidPtr, err := p.parseObject("not a url")
+	if err != nil {
+		return 0
+	}
+	defer p.freeUrlObject(invalidPtr)
+
+	fn := p.getFunction("ada_get_components")
+	if fn == nil {
+		return -1
+	}
+	results, err := p.call(fn, uint64(validPtr))
+	if err != nil {
+		return 0
+	}
+	if uint32(results[0]) < validPtr {
+		return -1
+	}
+	flag := uint32(results[0]) - validPtr + componentsSize
+
+	valid, ok := p.module.Memory().ReadByte(validPtr + flag)
+	invalid, ok2 := p.module.Memory().ReadByte(invalidPtr + flag)
+	if !ok || !ok2 || valid != 1 || invalid != 0 ||
+		!p.callAdaBoolFunction("ada_is_valid", validPtr) || p.callAdaBoolFunction("ada_is_valid", invalidPtr) {
+		return -1
+	}
+	return int32(flag)
+}
+
+// parseObject parses the given string into an ada_url object, valid or not
+func (p *Parser) parseObject(s string) (uint32, error) {
+	fn := p.getFunction("ada_parse")
+	if fn == nil {
+		return 0, engineFailure("ada_parse function not found")
+	}
+	args, release, err := p.writeStringArgs(s)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	results, err := p.call(fn, args...)
+	if err != nil {
+		return 0, err
+	}
+	if uint32(results[0]) == 0 {
+		return 0, engineFailure("empty url object!")
+	}
+	return uint32(results[0]), nil
+}
+
+// writeBatch copies the strings back to back into the batch buffer of the parser,
+// growing it if needed, and returns the WASM address of each of them
+func (p *Parser) writeBatch(strs []string) ([]uint32, error) {
+	total := 0
+	for _, s := range strs {
+		total += len(s)
+	}
+	if total > math.MaxUint32 {
+		return nil, engineFailure("batch too large for WASM memory")
+	}
+
+	if uint32(total) > p.batchCap {
+		if p.batchBuf != 0 {
+			p.wasmFree(p.batchBuf)
+			p.batchBuf, p.batchCap = 0, 0
+		}
+		ptr, err := p.wasmMalloc(uint32(total))
+		if err != nil {
+			return nil, err
+		}
+		p.batchBuf, p.batchCap = ptr, uint32(total)
+	}
+
+	buf := make([]byte, 0, total)
+	offsets := make([]uint32, len(strs))
+	for i, s := range strs {
+		offsets[i] = p.batchBuf + uint32(len(buf))
+		buf = append(buf, s...)
+	}
+	if !p.module.Memory().Write(p.batchBuf, buf) {
+		return nil, engineFailure("failed to write batch to WASM memory")
+	}
+	return offsets, nil
+}
+
+// failBatch reports the same failure for every input of a batch
+func failBatch(inputs []string, op, base, reason string, err error) []BatchResult {
+	results := make([]BatchResult, len(inputs))
+	for i, input := range inputs {
+		results[i].Err = newParseError(op, input, base, reason, err)
+	}
+	return results
+}
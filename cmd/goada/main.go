@@ -0,0 +1,221 @@
+// Command goada inspects and normalizes URLs with the WHATWG URL parser of goadawasm.
+//
+// Usage:
+//
+//	goada parse [-base URL] [-json] [-diagram] URL
+//	goada normalize [-base URL] [-workers N] < urls.txt
+//
+// parse prints every component of a URL, its host type, scheme type and origin,
+// and with -diagram the offsets of the components inside the parser.
+// normalize reads newline-delimited URLs from stdin and writes their serialization to stdout,
+// reporting invalid lines on stderr.
+//
+// An interrupt stops normalize, even while it waits for input; a second one kills the process.
+//
+// The exit code is 0 on success, 1 if an input is not a valid URL and 2 on usage errors.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+// Exit codes
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+)
+
+const usage = `Usage:
+  goada parse [-base URL] [-json] [-diagram] URL
+  goada normalize [-base URL] [-workers N] < urls.txt
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	// The first interrupt cancels ctx; restoring the default behavior lets a second one kill the process
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run runs the subcommand named by args[0] and returns the exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "parse":
+		return runParse(args[1:], stdout, stderr)
+	case "normalize":
+		return runNormalize(ctx, args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "goada: unknown command %q\n%s", args[0], usage)
+		return exitUsage
+	}
+}
+
+// urlInfo is the JSON output of the parse command
+type urlInfo struct {
+	Href       string `json:"href"`
+	Protocol   string `json:"protocol"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	Host       string `json:"host"`
+	Hostname   string `json:"hostname"`
+	Port       string `json:"port"`
+	Pathname   string `json:"pathname"`
+	Search     string `json:"search"`
+	Hash       string `json:"hash"`
+	Origin     string `json:"origin"`
+	HostType   string `json:"host_type"`
+	SchemeType string `json:"scheme_type"`
+	Diagram    string `json:"diagram,omitempty"`
+}
+
+// runParse parses a single URL and prints its components
+func runParse(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("goada parse", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	base := flags.String("base", "", "resolve the input against this base URL")
+	asJSON := flags.Bool("json", false, "print the components as JSON")
+	diagram := flags.Bool("diagram", false, "also print the component offsets diagram")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	var url *goadawasm.Url
+	var err error
+	if *base != "" {
+		url, err = goadawasm.NewWithBase(flags.Arg(0), *base)
+	} else {
+		url, err = goadawasm.New(flags.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "goada:", err)
+		return exitInvalid
+	}
+	defer url.Free()
+
+	record, err := url.Detach()
+	if err != nil {
+		fmt.Fprintln(stderr, "goada:", err)
+		return exitInvalid
+	}
+
+	info := urlInfo{
+		Href:       record.Href(),
+		Protocol:   record.Protocol(),
+		Username:   record.Username(),
+		Password:   record.Password(),
+		Host:       record.Host(),
+		Hostname:   record.Hostname(),
+		Port:       record.Port(),
+		Pathname:   record.Pathname(),
+		Search:     record.Search(),
+		Hash:       record.Hash(),
+		Origin:     record.Origin(),
+		HostType:   record.HostType().String(),
+		SchemeType: record.SchemeType().String(),
+	}
+	if *diagram {
+		if info.Diagram, err = url.Diagram(); err != nil {
+			fmt.Fprintln(stderr, "goada:", err)
+			return exitInvalid
+		}
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(info); err != nil {
+			fmt.Fprintln(stderr, "goada:", err)
+			return exitInvalid
+		}
+		return exitOK
+	}
+
+	table := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	for _, row := range [][2]string{
+		{"href", info.Href},
+		{"protocol", info.Protocol},
+		{"username", info.Username},
+		{"password", info.Password},
+		{"host", info.Host},
+		{"hostname", info.Hostname},
+		{"port", info.Port},
+		{"pathname", info.Pathname},
+		{"search", info.Search},
+		{"hash", info.Hash},
+		{"origin", info.Origin},
+		{"host type", info.HostType},
+		{"scheme type", info.SchemeType},
+	} {
+		fmt.Fprintf(table, "%s\t%s\n", row[0], row[1])
+	}
+	table.Flush()
+	if info.Diagram != "" {
+		fmt.Fprintln(stdout)
+		fmt.Fprint(stdout, info.Diagram)
+	}
+	return exitOK
+}
+
+// runNormalize normalizes the URLs read from stdin, one per line
+func runNormalize(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("goada normalize", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	base := flags.String("base", "", "resolve every line against this base URL")
+	workers := flags.Int("workers", 0, "number of lines parsed in parallel (default: number of CPUs)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	invalid := false
+	err := goadawasm.NormalizeStream(ctx, stdin, stdout, &goadawasm.NormalizeOptions{
+		Workers: *workers,
+		Base:    *base,
+		OnInvalid: func(line int, input string, err error) {
+			invalid = true
+			fmt.Fprintf(stderr, "goada: line %d: %v\n", line, err)
+		},
+	})
+	if err != nil {
+		// NormalizeStream leaves a read in progress to the owner of stdin, which ends it by closing stdin
+		if closer, ok := stdin.(io.Closer); ok {
+			closer.Close()
+		}
+		fmt.Fprintln(stderr, "goada:", err)
+		return exitInvalid
+	}
+	if invalid {
+		return exitInvalid
+	}
+	return exitOK
+}
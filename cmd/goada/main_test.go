@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTable(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(t.Context(), []string{"parse", "-base", "https://example.com/a/", "b?q#h"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr.String())
+	}
+
+	for _, row := range []string{
+		"href         https://example.com/a/b?q#h",
+		"origin       https://example.com",
+		"host type    default",
+		"scheme type  https",
+	} {
+		if !strings.Contains(stdout.String(), row+"\n") {
+			t.Errorf("expected row %q in:\n%s", row, stdout.String())
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(t.Context(), []string{"parse", "-json", "https://user@[::1]:8080/p"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr.String())
+	}
+
+	var info urlInfo
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		t.Fatalf("failed to decode output: %v", err)
+	}
+	expected := urlInfo{
+		Href:       "https://user@[::1]:8080/p",
+		Protocol:   "https:",
+		Username:   "user",
+		Host:       "[::1]:8080",
+		Hostname:   "[::1]",
+		Port:       "8080",
+		Pathname:   "/p",
+		Origin:     "https://[::1]:8080",
+		HostType:   "ipv6",
+		SchemeType: "https",
+	}
+	if info != expected {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run(t.Context(), []string{"parse", "not a url"}, nil, &stdout, &stderr); code != exitInvalid {
+		t.Errorf("expected exit code %d, got %d", exitInvalid, code)
+	}
+	if !strings.Contains(stderr.String(), "invalid input") {
+		t.Errorf("expected the reason on stderr, got %q", stderr.String())
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader("HTTPS://Example.com/./a\nhttps://exa mple.com\n/b\n")
+	code := run(t.Context(), []string{"normalize", "-base", "https://example.com/"}, stdin, &stdout, &stderr)
+	if code != exitInvalid {
+		t.Errorf("expected exit code %d, got %d", exitInvalid, code)
+	}
+	if got := stdout.String(); got != "https://example.com/a\nhttps://example.com/b\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+	if !strings.Contains(stderr.String(), "line 2:") {
+		t.Errorf("expected line 2 reported on stderr, got %q", stderr.String())
+	}
+}
+
+func TestNormalizeInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	stdin, pw := io.Pipe()
+	defer pw.Close()
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	done := make(chan int, 1)
+	var stdout, stderr bytes.Buffer
+	go func() {
+		done <- run(ctx, []string{"normalize"}, stdin, &stdout, &stderr)
+	}()
+	select {
+	case code := <-done:
+		if code != exitInvalid {
+			t.Errorf("expected exit code %d, got %d", exitInvalid, code)
+		}
+		if !strings.Contains(stderr.String(), "context canceled") {
+			t.Errorf("expected the cancellation reported on stderr, got %q", stderr.String())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected normalize to stop while waiting for input")
+	}
+
+	// The command owns stdin, so it closes it to end the read in progress
+	if _, err := pw.Write([]byte("https://example.com/\n")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected stdin to be closed, got %v", err)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"frobnicate"}, {"parse"}, {"parse", "-nope", "x"}, {"normalize", "extra"}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(t.Context(), args, strings.NewReader(""), &stdout, &stderr); code != exitUsage {
+			t.Errorf("expected exit code %d for %q, got %d", exitUsage, args, code)
+		}
+	}
+}
+
+func TestParseDiagram(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run(t.Context(), []string{"parse", "-diagram", "https://example.com/p?q"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("expected exit code %d, got %d: %s", exitOK, code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "pathname_start 19") {
+		t.Errorf("expected the diagram in:\n%s", stdout.String())
+	}
+}
@@ -0,0 +1,225 @@
+package goadawasm
+
+import (
+	"context"
+)
+
+// InterruptedError reports WASM calls that were stopped because their context was done.
+// The module instance they ran on is discarded rather than returned to the pool, so a Url
+// whose setter was interrupted is no longer usable and should be freed.
+type InterruptedError struct {
+	Op  string // Operation that was interrupted, e.g. "parse"
+	Err error  // The context error, context.Canceled or context.DeadlineExceeded
+}
+
+func (e *InterruptedError) Error() string {
+	return e.Op + " interrupted: " + e.Err.Error()
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// ParseContext parses the given string into a URL, stopping early when ctx is done.
+// The returned URL lives in an interruptible WASM instance, so its context-taking setters
+// can be stopped mid-call as well; this makes every call on it somewhat slower than on a URL from New,
+// and the URL keeps a whole WASM instance to itself.
+func ParseContext(ctx context.Context, urlstring string) (*Url, error) {
+	return defaultEngine().ParseContext(ctx, urlstring)
+}
+
+// ParseWithBaseContext parses the given strings into a URL with a base URL, stopping early when ctx is done.
+// See ParseContext for the cost of interruptible URLs.
+func ParseWithBaseContext(ctx context.Context, urlstring, basestring string) (*Url, error) {
+	return defaultEngine().ParseWithBaseContext(ctx, urlstring, basestring)
+}
+
+// ParseContext parses the given string into a URL using an interruptible parser of the engine
+func (e *Engine) ParseContext(ctx context.Context, urlstring string) (*Url, error) {
+	interruptible, err := e.interruptibleEngine()
+	if err != nil {
+		return nil, newParseError("parse", urlstring, "", ReasonEngineFailure, err)
+	}
+	parser, err := interruptible.acquireParser()
+	if err != nil {
+		return nil, newParseError("parse", urlstring, "", ReasonEngineFailure, err)
+	}
+	defer interruptible.releaseParser(parser)
+
+	return parser.ParseContext(ctx, urlstring)
+}
+
+// ParseWithBaseContext parses the given strings into a URL with a base URL using an interruptible
+// parser of the engine
+func (e *Engine) ParseWithBaseContext(ctx context.Context, urlstring, basestring string) (*Url, error) {
+	interruptible, err := e.interruptibleEngine()
+	if err != nil {
+		return nil, newParseError("parse with base", urlstring, basestring, ReasonEngineFailure, err)
+	}
+	parser, err := interruptible.acquireParser()
+	if err != nil {
+		return nil, newParseError("parse with base", urlstring, basestring, ReasonEngineFailure, err)
+	}
+	defer interruptible.releaseParser(parser)
+
+	return parser.ParseWithBaseContext(ctx, urlstring, basestring)
+}
+
+// ParseContext parses the given string into a URL using the parser, stopping early when ctx is done.
+// Calls already running are only stopped if the parser comes from an interruptible engine.
+func (p *Parser) ParseContext(ctx context.Context, urlstring string) (*Url, error) {
+	var url *Url
+	var parseErr error
+	err := p.runContext(ctx, "parse", func() {
+		url, parseErr = p.New(urlstring)
+	})
+	if err != nil {
+		if url != nil {
+			url.drop()
+		}
+		return nil, err
+	}
+	return url, parseErr
+}
+
+// ParseWithBaseContext parses the given strings into a URL with a base URL using the parser,
+// stopping early when ctx is done
+func (p *Parser) ParseWithBaseContext(ctx context.Context, urlstring, basestring string) (*Url, error) {
+	var url *Url
+	var parseErr error
+	err := p.runContext(ctx, "parse with base", func() {
+		url, parseErr = p.NewWithBase(urlstring, basestring)
+	})
+	if err != nil {
+		if url != nil {
+			url.drop()
+		}
+		return nil, err
+	}
+	return url, parseErr
+}
+
+// runContext runs fn with every WASM call of the parser bound to ctx.
+// For interruptible parsers wazero closes the module instance when ctx is done mid-call,
+// in which case the parser is discarded and an InterruptedError is returned.
+// Other parsers only observe ctx before fn starts.
+func (p *Parser) runContext(ctx context.Context, op string, fn func()) error {
+	if err := ctx.Err(); err != nil {
+		return &InterruptedError{Op: op, Err: err}
+	}
+	if p.module.IsClosed() {
+		return engineFailure(op + ": WASM module instance is closed")
+	}
+
+	prev := p.ctx
+	p.ctx = ctx
+	fn()
+	p.ctx = prev
+
+	if p.module.IsClosed() {
+		p.discard()
+		// Without a done context the instance failed on its own, which fn reports
+		if err := ctx.Err(); err != nil {
+			return &InterruptedError{Op: op, Err: err}
+		}
+	}
+	return nil
+}
+
+// drop forgets a URL whose module instance was closed under it, leaving nothing to free
+func (u *Url) drop() {
+	u.cleanup.Stop()
+	p := u.parser
+	p.detach(u.object)
+	u.home.Store(nil)
+	u.parser = nil
+	p.release()
+}
+
+// Helper function to call a bool setter with WASM calls bound to ctx
+func (u *Url) setContext(ctx context.Context, op, funcName, value string) (bool, error) {
+	var ok bool
+	err := u.parser.runContext(ctx, op, func() {
+		ok = u.callSetterBool(funcName, value)
+	})
+	if err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// SetHrefContext sets the full URL, stopping early when ctx is done.
+// Like the other context-taking setters, it can only stop a running call on URLs
+// created by ParseContext or ParseWithBaseContext; for other URLs ctx is checked up front.
+func (u *Url) SetHrefContext(ctx context.Context, s string) (bool, error) {
+	u.lock()
+	defer u.unlock()
+	return u.setContext(ctx, "set href", "ada_set_href", s)
+}
+
+// SetHostContext sets the host, stopping early when ctx is done
+func (u *Url) SetHostContext(ctx context.Context, s string) (bool, error) {
+	u.lock()
+	defer u.unlock()
+	return u.setContext(ctx, "set host", "ada_set_host", s)
+}
+
+// SetHostnameContext sets the hostname, stopping early when ctx is done
+func (u *Url) SetHostnameContext(ctx context.Context, s string) (bool, error) {
+	u.lock()
+	defer u.unlock()
+	return u.setContext(ctx, "set hostname", "ada_set_hostname", s)
+}
+
+// SetProtocolContext sets the protocol, stopping early when ctx is done
+func (u *Url) SetProtocolContext(ctx context.Context, s string) (bool, error) {
+	u.lock()
+	defer u.unlock()
+	return u.setContext(ctx, "set protocol", "ada_set_protocol", s)
+}
+
+// SetUsernameContext sets the username, stopping early when ctx is done
+func (u *Url) SetUsernameContext(ctx context.Context, s string) (bool, error) {
+	u.lock()
+	defer u.unlock()
+	return u.setContext(ctx, "set username", "ada_set_username", s)
+}
+
+// SetPasswordContext sets the password, stopping early when ctx is done
+func (u *Url) SetPasswordContext(ctx context.Context, s string) (bool, error) {
+	u.lock()
+	defer u.unlock()
+	return u.setContext(ctx, "set password", "ada_set_password", s)
+}
+
+// SetPortContext sets the port, stopping early when ctx is done
+func (u *Url) SetPortContext(ctx context.Context, s string) (bool, error) {
+	u.lock()
+	defer u.unlock()
+	return u.setContext(ctx, "set port", "ada_set_port", s)
+}
+
+// SetPathnameContext sets the pathname, stopping early when ctx is done
+func (u *Url) SetPathnameContext(ctx context.Context, s string) (bool, error) {
+	u.lock()
+	defer u.unlock()
+	return u.setContext(ctx, "set pathname", "ada_set_pathname", s)
+}
+
+// SetSearchContext sets the search/query string, stopping early when ctx is done
+func (u *Url) SetSearchContext(ctx context.Context, s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.parser.runContext(ctx, "set search", func() {
+		u.callSetterVoid("ada_set_search", s)
+	})
+}
+
+// SetHashContext sets the hash/fragment, stopping early when ctx is done
+func (u *Url) SetHashContext(ctx context.Context, s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.parser.runContext(ctx, "set hash", func() {
+		u.callSetterVoid("ada_set_hash", s)
+	})
+}
@@ -0,0 +1,67 @@
+package goadawasm
+
+import "encoding/binary"
+
+// Mangled names of the C++ functions behind Diagram, exported since ada.wasm is linked with --export-all
+const (
+	toDiagramFunc  = "_ZNK3ada14url_aggregator10to_diagramEv"
+	stringDtorFunc = "_ZNSt3__212basic_stringIcNS_11char_traitsIcEENS_9allocatorIcEEED2Ev"
+)
+
+// stdStringSize is the size of a libc++ std::string on wasm32
+const stdStringSize = 12
+
+// Diagram returns ada's annotated rendering of the URL's internal component offsets
+// (protocol_end, username_end, host_start, host_end, port, pathname_start, search_start and hash_start),
+// as printed by ada::url_aggregator::to_diagram. It is meant for debugging parser disagreements.
+func (u *Url) Diagram() (string, error) {
+	u.lock()
+	defer u.unlock()
+	fn := u.parser.getFunction(toDiagramFunc)
+	if fn == nil {
+		return "", engineFailure("url_aggregator::to_diagram function not found")
+	}
+
+	// The std::string is returned through a pointer to its storage, followed by this.
+	// An ada_url points to an ada::result<url_aggregator>, which holds the url_aggregator first.
+	resultPtr, err := u.parser.wasmMalloc(stdStringSize)
+	if err != nil {
+		return "", err
+	}
+	defer u.parser.wasmFree(resultPtr)
+
+	if _, err := u.parser.call(fn, uint64(resultPtr), uint64(u.cpointer)); err != nil {
+		return "", err
+	}
+	return u.parser.readStdString(resultPtr)
+}
+
+// Helper function to copy out a libc++ std::string stored at ptr and destroy it
+func (p *Parser) readStdString(ptr uint32) (string, error) {
+	dtor := p.getFunction(stringDtorFunc)
+	if dtor == nil {
+		return "", engineFailure("std::string destructor not found")
+	}
+
+	rep, ok := p.module.Memory().Read(ptr, stdStringSize)
+	if !ok {
+		return "", engineFailure("failed to read std::string from memory")
+	}
+
+	// ada.wasm uses the alternate libc++ layout: {data, size, capacity} with the highest bit
+	// of the capacity set for heap-allocated strings, and {chars[11], size} for inline ones
+	var result string
+	var err error
+	if rep[11]&0x80 != 0 {
+		result, err = p.readWasmString(binary.LittleEndian.Uint32(rep[0:4]), binary.LittleEndian.Uint32(rep[4:8]))
+	} else if length := rep[11]; length < stdStringSize {
+		result = string(rep[:length])
+	} else {
+		err = engineFailure("malformed std::string in memory")
+	}
+
+	if _, dtorErr := p.call(dtor, uint64(ptr)); dtorErr != nil {
+		return "", dtorErr
+	}
+	return result, err
+}
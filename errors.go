@@ -0,0 +1,134 @@
+package goadawasm
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrValueRejected = errors.New("value rejected")
+	ErrNotApplicable = errors.New("setter not applicable to the url")
+	ErrEngineFailure = errors.New("engine failure")
+)
+
+// Reasons reported by ParseError and SetterError
+const (
+	ReasonEmptyInput    = "empty input"               // Both the input and the base are empty
+	ReasonInvalidInput  = "invalid input"             // The input is not a valid URL (against the base, if any)
+	ReasonInvalidBase   = "invalid base"              // The base is not a valid URL, so no input can be resolved against it
+	ReasonValueRejected = "value rejected"            // The setter refused the value under the WHATWG rules
+	ReasonNotApplicable = "not applicable to the url" // The URL cannot have the component, e.g. a host with an opaque path
+	ReasonEngineFailure = "engine failure"            // The engine could not run the call, regardless of the input
+)
+
+// maxErrorInputLength is the number of bytes of an input or value kept in an error
+const maxErrorInputLength = 256
+
+// ParseError reports a URL that could not be parsed.
+// It matches ErrEmptyString or ErrInvalidUrl with errors.Is, depending on the reason.
+type ParseError struct {
+	Op     string // Operation that failed, "parse" or "parse with base"
+	Input  string // The input, truncated to a size fit for logging
+	Base   string // The base, truncated like Input; empty for "parse"
+	Reason string // One of the Reason constants
+	Err    error  // ErrEmptyString, ErrInvalidUrl or the failure of the engine
+}
+
+// newParseError creates a ParseError, truncating the input and base
+func newParseError(op, input, base, reason string, err error) *ParseError {
+	return &ParseError{
+		Op:     op,
+		Input:  truncateInput(input),
+		Base:   truncateInput(base),
+		Reason: reason,
+		Err:    err,
+	}
+}
+
+func (e *ParseError) Error() string {
+	msg := e.Op + " " + strconv.Quote(e.Input)
+	if e.Op == "parse with base" {
+		msg += " against " + strconv.Quote(e.Base)
+	}
+	if e.Reason == ReasonEngineFailure {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg + ": " + e.Reason
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// SetterError reports a value a URL setter did not apply.
+// It matches ErrValueRejected or ErrNotApplicable with errors.Is, depending on the reason.
+type SetterError struct {
+	Op     string // Operation that failed, e.g. "set host"
+	Value  string // The value, truncated to a size fit for logging
+	Reason string // ReasonValueRejected, ReasonNotApplicable or ReasonEngineFailure
+	Err    error  // ErrValueRejected, ErrNotApplicable or the failure of the engine
+}
+
+// newSetterError creates a SetterError, truncating the value
+func newSetterError(op, value, reason string, err error) *SetterError {
+	return &SetterError{
+		Op:     op,
+		Value:  truncateInput(value),
+		Reason: reason,
+		Err:    err,
+	}
+}
+
+func (e *SetterError) Error() string {
+	msg := e.Op + " " + strconv.Quote(e.Value)
+	if e.Reason == ReasonEngineFailure {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg + ": " + e.Reason
+}
+
+func (e *SetterError) Unwrap() error {
+	return e.Err
+}
+
+// errDiscarded reports calls on an instance discarded after an earlier failure
+var errDiscarded = errors.New("WASM module instance was discarded")
+
+// engineError reports a failure inside the WASM instance, such as a trap.
+// It matches both ErrEngineFailure and the underlying error with errors.Is.
+type engineError struct {
+	err error
+}
+
+func (e *engineError) Error() string {
+	// Keep log lines short by leaving out the WASM stack trace wazero appends to traps
+	msg, _, _ := strings.Cut(e.err.Error(), "\n")
+	return "engine failure: " + msg
+}
+
+func (e *engineError) Unwrap() []error {
+	return []error{ErrEngineFailure, e.err}
+}
+
+// engineFailure creates an engineError for a failure described by msg
+func engineFailure(msg string) error {
+	return &engineError{err: errors.New(msg)}
+}
+
+// errEngineClosed reports calls on a closed engine as an engine failure matching ErrEngineClosed
+var errEngineClosed error = &engineError{err: ErrEngineClosed}
+
+// truncateInput cuts s down to maxErrorInputLength bytes without splitting a UTF-8 sequence,
+// noting the original length
+func truncateInput(s string) string {
+	if len(s) <= maxErrorInputLength {
+		return s
+	}
+	end := maxErrorInputLength
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end] + "... (" + strconv.Itoa(len(s)) + " bytes)"
+}
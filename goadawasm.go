@@ -6,10 +6,9 @@ import (
 	"errors"
 	"runtime"
 	"sync"
+	"sync/atomic"
 
-	"github.com/tetratelabs/wazero"
 	"github.com/tetratelabs/wazero/api"
-	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
 )
 
 //go:embed embed/ada.wasm
@@ -20,104 +19,156 @@ var (
 	ErrInvalidUrl  = errors.New("invalid url")
 )
 
-// Global WASM runtime and compiled module - initialized once and shared
-var (
-	globalRuntime  wazero.Runtime
-	compiledModule wazero.CompiledModule
-	initOnce       sync.Once
-	globalCtx      context.Context
-)
-
-// Parser represents a WASM module instance for concurrent URL parsing
+// Parser represents a WASM module instance, which may hold the objects of many URLs.
+// Calls on it must be serialized with its lock; the Engine and Url methods take care of that.
 type Parser struct {
 	ctx       context.Context
+	engine    *Engine // Engine the module instance belongs to
 	module    api.Module
 	funcCache map[string]api.Function
 	mutex     sync.RWMutex
+	refs      atomic.Int32  // Number of live objects owning this parser
+	discarded atomic.Bool   // Set once the module instance must not be reused
+	retired   atomic.Bool   // Set once the instance outgrew the engine limits; closed when unused
+	closed    atomic.Bool   // Set once Close ran
+	allocs    atomic.Uint64 // Number of URL and search params objects created in the instance
+	lock      sync.Mutex    // Serializes calls on the instance
+	batchBuf  uint32        // Grow-only WASM buffer holding the inputs of a batch, guarded by lock
+	batchCap  uint32        // Size of batchBuf in bytes
+	floor     uint64        // Memory size after taking over the objects of a retired instance, guarded by lock
+	objects   []*object     // Live objects, moved to another instance when this one is retired; guarded by lock
 }
 
-// Initialize the global WASM runtime and compiled module (done once)
-func initGlobalWasm() {
-	globalCtx = context.Background()
-	globalRuntime = wazero.NewRuntime(globalCtx)
+func New(urlstring string) (*Url, error) {
+	return defaultEngine().New(urlstring)
+}
 
-	// Instantiate WASI
-	wasi_snapshot_preview1.MustInstantiate(globalCtx, globalRuntime)
+func NewWithBase(urlstring, basestring string) (*Url, error) {
+	return defaultEngine().NewWithBase(urlstring, basestring)
+}
 
-	// Compile the Ada WASM module once for reuse
-	var err error
-	compiledModule, err = globalRuntime.CompileModule(globalCtx, adaWasm)
+// New parses the given string into a URL using a parser of the engine
+func (e *Engine) New(urlstring string) (*Url, error) {
+	parser, err := e.acquireParser()
 	if err != nil {
-		panic("failed to compile Ada WASM module: " + err.Error())
+		return nil, newParseError("parse", urlstring, "", ReasonEngineFailure, err)
 	}
-}
+	defer e.releaseParser(parser)
 
-// ensureGlobalInit ensures the global WASM runtime is initialized
-func ensureGlobalInit() {
-	initOnce.Do(initGlobalWasm)
+	return parser.New(urlstring)
 }
 
-// newParser creates a new Parser with its own WASM module instance
-func newParser() (*Parser, error) {
-	ensureGlobalInit()
-
-	// Create a new module instance from the compiled module
-	module, err := globalRuntime.InstantiateModule(globalCtx, compiledModule, wazero.NewModuleConfig().WithName(""))
+// NewWithBase parses the given strings into a URL with a base URL using a parser of the engine
+func (e *Engine) NewWithBase(urlstring, basestring string) (*Url, error) {
+	parser, err := e.acquireParser()
 	if err != nil {
-		return nil, errors.New("failed to instantiate Ada WASM module: " + err.Error())
+		return nil, newParseError("parse with base", urlstring, basestring, ReasonEngineFailure, err)
 	}
+	defer e.releaseParser(parser)
 
-	parser := &Parser{
-		ctx:       globalCtx,
-		module:    module,
-		funcCache: make(map[string]api.Function),
-	}
-	runtime.SetFinalizer(parser, (*Parser).Close)
-	return parser, nil
+	return parser.NewWithBase(urlstring, basestring)
 }
 
-var parserPool = sync.Pool{
-	New: func() any {
-		parser, err := newParser()
-		if err != nil {
-			panic("failed to create Parser: " + err.Error())
-		}
-		return parser
-	},
+// CanParse checks if the given string parses as a valid URL without allocating a Url
+func CanParse(urlstring string) bool {
+	return defaultEngine().CanParse(urlstring)
 }
 
-func New(urlstring string) (*Url, error) {
-	parser := parserPool.Get().(*Parser)
-	// Don't return parser yet - Url will own it
+// CanParseWithBase checks if the given string parses as a valid URL against the base
+// without allocating a Url
+func CanParseWithBase(urlstring, basestring string) bool {
+	return defaultEngine().CanParseWithBase(urlstring, basestring)
+}
 
-	url, err := parser.New(urlstring)
+// CanParse checks if the given string parses as a valid URL using a parser of the engine
+func (e *Engine) CanParse(urlstring string) bool {
+	parser, err := e.acquireParser()
 	if err != nil {
-		parserPool.Put(parser) // Return on error
-		return nil, err
+		return false
 	}
+	defer e.releaseParser(parser)
 
-	return url, nil
+	return parser.CanParse(urlstring)
 }
 
-func NewWithBase(urlstring, basestring string) (*Url, error) {
-	parser := parserPool.Get().(*Parser)
-	// Don't return parser yet - Url will own it
-
-	url, err := parser.NewWithBase(urlstring, basestring)
+// CanParseWithBase checks if the given string parses as a valid URL against the base
+// using a parser of the engine
+func (e *Engine) CanParseWithBase(urlstring, basestring string) bool {
+	parser, err := e.acquireParser()
 	if err != nil {
-		parserPool.Put(parser) // Return on error
-		return nil, err
+		return false
 	}
+	defer e.releaseParser(parser)
 
-	return url, nil
+	return parser.CanParseWithBase(urlstring, basestring)
+}
+
+// discard closes the module instance and keeps the parser out of the pool and the shards from now on
+func (p *Parser) discard() {
+	p.discarded.Store(true)
+	p.Close()
 }
 
 // Close closes the parser and releases its WASM module instance
 func (p *Parser) Close() error {
-	if p.module != nil {
-		return p.module.Close(p.ctx)
+	if p.module == nil || p.closed.Swap(true) {
+		return nil
 	}
-	return nil
+	p.engine.instances.Add(-1)
+	return p.module.Close(p.ctx)
+}
+
+// retain records a new object owning the parser
+func (p *Parser) retain() {
+	p.refs.Add(1)
+}
+
+// release drops an owning object. Once none are left, a retired parser is closed
+// and an exclusively owned one is returned to the pool.
+func (p *Parser) release() {
+	if p.refs.Add(-1) != 0 {
+		return
+	}
+	if p.retired.Load() {
+		p.closeIfUnused()
+	} else if !p.engine.shared() {
+		p.engine.putParser(p)
+	}
+}
+
+// unlock ends a call on the instance, retiring the instance if the call made it exceed the engine limits
+func (p *Parser) unlock() {
+	retire := !p.retired.Load() && p.engine.exceedsLimits(p)
+	p.lock.Unlock()
+	if retire {
+		p.engine.retire(p)
+	}
+}
+
+// closeIfUnused closes the instance unless an object still lives in it
+func (p *Parser) closeIfUnused() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.refs.Load() == 0 {
+		p.Close()
+	}
+}
+
+// call calls a WASM function of the module instance. A failed call, e.g. a trap, leaves the
+// instance in an unknown state (the C stack pointer is not restored, the heap may be corrupt),
+// so the parser is discarded and the error is reported as ErrEngineFailure.
+func (p *Parser) call(fn api.Function, args ...uint64) ([]uint64, error) {
+	// wazero only refuses calls on closed modules when watching for termination
+	if p.discarded.Load() {
+		return nil, &engineError{err: errDiscarded}
+	}
+
+	results, err := fn.Call(p.ctx, args...)
+	if err != nil {
+		p.discard()
+		return nil, &engineError{err: err}
+	}
+	return results, nil
 }
 
 // getFunction gets a cached function or loads it from the module
@@ -146,33 +197,71 @@ func (p *Parser) getFunction(name string) api.Function {
 
 // URL represents a parsed URL backed by Ada WASM implementation
 type Url struct {
-	parser   *Parser // Reference to the parser that created this URL
-	cpointer uint32  // Pointer to ada_url object in WASM memory
+	*object                 // The ada_url object in WASM memory
+	cleanup runtime.Cleanup // Frees the object once the URL is collected; zero for URLs of a batch
+	guard   usageGuard      // Detects unsynchronized concurrent use in debug builds
+}
+
+// lock serializes a call with the other users of the URL's WASM instance,
+// following the URL if it was moved while waiting for the lock
+func (u *Url) lock() {
+	u.guard.enter()
+	u.object.lock()
+}
+
+// unlock ends a call started with lock
+func (u *Url) unlock() {
+	u.object.unlock()
+	u.guard.exit()
+}
+
+// Err returns an error matching ErrEngineFailure once the URL is no longer usable, because
+// the engine was closed or the WASM instance holding it was discarded. URLs share instances,
+// so an input that traps discards the URLs living next to it too; their getters then return
+// zero values, which Err tells apart from empty components.
+func (u *Url) Err() error {
+	return u.object.err()
+}
+
+// err reports whether objects living in the parser are no longer usable
+func (p *Parser) err() error {
+	if p.engine.closed.Load() {
+		return errEngineClosed
+	}
+	if p.discarded.Load() || p.closed.Load() {
+		return &engineError{err: errDiscarded}
+	}
+	return nil
 }
 
 // Helper function to allocate memory in WASM
 func (p *Parser) wasmMalloc(size uint32) (uint32, error) {
 	malloc := p.getFunction("malloc")
 	if malloc == nil {
-		return 0, errors.New("malloc function not found")
+		return 0, engineFailure("malloc function not found")
 	}
 
-	results, err := malloc.Call(p.ctx, uint64(size))
+	results, err := p.call(malloc, uint64(size))
 	if err != nil {
 		return 0, err
 	}
 
-	return uint32(results[0]), nil
+	ptr := uint32(results[0])
+	if ptr == 0 {
+		// The instance itself is fine, its memory is just exhausted
+		return 0, engineFailure("out of WASM memory")
+	}
+	return ptr, nil
 }
 
 // Helper function to free memory in WASM
 func (p *Parser) wasmFree(ptr uint32) error {
 	free := p.getFunction("free")
 	if free == nil {
-		return errors.New("free function not found")
+		return engineFailure("free function not found")
 	}
 
-	_, err := free.Call(p.ctx, uint64(ptr))
+	_, err := p.call(free, uint64(ptr))
 	return err
 }
 
@@ -190,37 +279,46 @@ func (p *Parser) writeStringToWasm(s string) (uint32, error) {
 	ok := p.module.Memory().Write(ptr, []byte(s))
 	if !ok {
 		p.wasmFree(ptr)
-		return 0, errors.New("failed to write string to WASM memory")
+		return 0, engineFailure("failed to write string to WASM memory")
 	}
 
 	return ptr, nil
 }
 
-// Helper function to read ada_string from WASM memory
-func (p *Parser) readAdaString(fn api.Function, urlPtr uint32) (string, error) {
+// Helper function to call an Ada function returning ada_string and get its buffer pointer and length
+func (p *Parser) callAdaStringFunction(fn api.Function, args ...uint64) (uint32, uint32, error) {
 	// Allocate memory for the ada_string result struct
 	resultPtr, err := p.wasmMalloc(8) // 8 bytes for ada_string struct
 	if err != nil {
-		return "", err
+		return 0, 0, err
 	}
 	defer p.wasmFree(resultPtr)
 
 	// Call the function with WASM calling convention for struct returns
-	_, err = fn.Call(p.ctx, uint64(resultPtr), uint64(urlPtr))
+	_, err = p.call(fn, append([]uint64{uint64(resultPtr)}, args...)...)
 	if err != nil {
-		return "", err
+		return 0, 0, err
 	}
 
 	// Read the ada_string result from memory
 	resultBytes, ok := p.module.Memory().Read(resultPtr, 8)
 	if !ok {
-		return "", errors.New("failed to read result struct from memory")
+		return 0, 0, engineFailure("failed to read result struct from memory")
 	}
 
-	// Extract buffer pointer and length (little-endian, 32-bit)
-	bufferPtr := uint32(resultBytes[0]) | uint32(resultBytes[1])<<8 | uint32(resultBytes[2])<<16 | uint32(resultBytes[3])<<24
-	length := uint32(resultBytes[4]) | uint32(resultBytes[5])<<8 | uint32(resultBytes[6])<<16 | uint32(resultBytes[7])<<24
+	bufferPtr, length := decodeAdaString(resultBytes)
+	return bufferPtr, length, nil
+}
+
+// Helper function to extract buffer pointer and length from an ada_string struct (little-endian, 32-bit)
+func decodeAdaString(b []byte) (uint32, uint32) {
+	bufferPtr := uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+	length := uint32(b[4]) | uint32(b[5])<<8 | uint32(b[6])<<16 | uint32(b[7])<<24
+	return bufferPtr, length
+}
 
+// Helper function to read a string of the given length from WASM memory
+func (p *Parser) readWasmString(bufferPtr, length uint32) (string, error) {
 	if bufferPtr == 0 || length == 0 {
 		return "", nil
 	}
@@ -228,12 +326,81 @@ func (p *Parser) readAdaString(fn api.Function, urlPtr uint32) (string, error) {
 	// Read the string data from WASM memory
 	stringBytes, ok := p.module.Memory().Read(bufferPtr, length)
 	if !ok {
-		return "", errors.New("failed to read string from memory")
+		return "", engineFailure("failed to read string from memory")
 	}
 
 	return string(stringBytes), nil
 }
 
+// Helper function to read ada_string from WASM memory
+func (p *Parser) readAdaString(fn api.Function, urlPtr uint32) (string, error) {
+	bufferPtr, length, err := p.callAdaStringFunction(fn, uint64(urlPtr))
+	if err != nil {
+		return "", err
+	}
+	return p.readWasmString(bufferPtr, length)
+}
+
+// Helper function to read ada_owned_string from WASM memory and release it with ada_free_owned_string
+func (p *Parser) readAdaOwnedString(fn api.Function, args ...uint64) (string, error) {
+	freeOwned := p.getFunction("ada_free_owned_string")
+	if freeOwned == nil {
+		return "", engineFailure("ada_free_owned_string function not found")
+	}
+
+	// Allocate memory for the ada_owned_string result struct
+	resultPtr, err := p.wasmMalloc(8) // 8 bytes for ada_owned_string struct
+	if err != nil {
+		return "", err
+	}
+	defer p.wasmFree(resultPtr)
+
+	_, err = p.call(fn, append([]uint64{uint64(resultPtr)}, args...)...)
+	if err != nil {
+		return "", err
+	}
+
+	resultBytes, ok := p.module.Memory().Read(resultPtr, 8)
+	if !ok {
+		return "", engineFailure("failed to read result struct from memory")
+	}
+	bufferPtr, length := decodeAdaString(resultBytes)
+
+	// Copy the string out before the owned buffer is released
+	result, readErr := p.readWasmString(bufferPtr, length)
+
+	// ada_owned_string is passed by value, which the WASM ABI lowers to a pointer to the struct
+	if _, err := p.call(freeOwned, uint64(resultPtr)); err != nil {
+		return "", err
+	}
+
+	return result, readErr
+}
+
+// Helper function to write strings to WASM memory as (pointer, length) argument pairs.
+// The returned function releases the written strings.
+func (p *Parser) writeStringArgs(strs ...string) ([]uint64, func(), error) {
+	ptrs := make([]uint32, 0, len(strs))
+	release := func() {
+		for _, ptr := range ptrs {
+			p.wasmFree(ptr)
+		}
+	}
+
+	args := make([]uint64, 0, 2*len(strs))
+	for _, s := range strs {
+		ptr, err := p.writeStringToWasm(s)
+		if err != nil {
+			release()
+			return nil, nil, err
+		}
+		ptrs = append(ptrs, ptr)
+		args = append(args, uint64(ptr), uint64(len(s)))
+	}
+
+	return args, release, nil
+}
+
 // Helper function to call a boolean-returning Ada function
 func (p *Parser) callAdaBoolFunction(funcName string, urlPtr uint32) bool {
 	fn := p.getFunction(funcName)
@@ -241,7 +408,7 @@ func (p *Parser) callAdaBoolFunction(funcName string, urlPtr uint32) bool {
 		return false
 	}
 
-	results, err := fn.Call(p.ctx, uint64(urlPtr))
+	results, err := p.call(fn, uint64(urlPtr))
 	if err != nil {
 		return false
 	}
@@ -249,172 +416,307 @@ func (p *Parser) callAdaBoolFunction(funcName string, urlPtr uint32) bool {
 	return results[0] != 0
 }
 
-// ada_free frees the URL object in WASM memory
-func (u *Url) ada_free() {
-	if u.cpointer != 0 {
-		adaFree := u.parser.getFunction("ada_free")
-		if adaFree != nil {
-			adaFree.Call(u.parser.ctx, uint64(u.cpointer))
-		}
-		u.cpointer = 0
+// Helper function to call a uint8-returning Ada function.
+// It returns invalidUint8 on failure, which lies outside the HostType and SchemeType enumerations.
+func (p *Parser) callAdaUint8Function(funcName string, urlPtr uint32) uint8 {
+	fn := p.getFunction(funcName)
+	if fn == nil {
+		return invalidUint8
 	}
+
+	results, err := p.call(fn, uint64(urlPtr))
+	if err != nil {
+		return invalidUint8
+	}
+
+	return uint8(results[0])
 }
 
-// New parses the given string into a URL using the parser
+// Helper function to call a void Ada function on a URL
+func (p *Parser) callAdaVoidFunction(funcName string, urlPtr uint32) {
+	fn := p.getFunction(funcName)
+	if fn == nil {
+		return
+	}
+
+	p.call(fn, uint64(urlPtr))
+}
+
+// New parses the given string into a URL using the parser.
+// Failures are reported as a *ParseError.
 func (p *Parser) New(urlstring string) (*Url, error) {
+	fail := func(reason string, err error) (*Url, error) {
+		return nil, newParseError("parse", urlstring, "", reason, err)
+	}
 	if len(urlstring) == 0 {
-		return nil, ErrEmptyString
+		return fail(ReasonEmptyInput, ErrEmptyString)
 	}
 
 	// Write URL string to WASM memory
 	urlPtr, err := p.writeStringToWasm(urlstring)
 	if err != nil {
-		return nil, err
+		return fail(ReasonEngineFailure, err)
 	}
 	defer p.wasmFree(urlPtr)
 
 	// Call ada_parse
 	parseFunc := p.getFunction("ada_parse")
 	if parseFunc == nil {
-		return nil, errors.New("ada_parse function not found")
+		return fail(ReasonEngineFailure, engineFailure("ada_parse function not found"))
 	}
 
-	results, err := parseFunc.Call(p.ctx, uint64(urlPtr), uint64(len(urlstring)))
+	results, err := p.call(parseFunc, uint64(urlPtr), uint64(len(urlstring)))
 	if err != nil {
-		return nil, err
+		return fail(ReasonEngineFailure, err)
 	}
 
 	urlObjPtr := uint32(results[0])
 	if urlObjPtr == 0 {
-		return nil, errors.Join(ErrInvalidUrl, errors.New("empty url object!"))
+		return fail(ReasonEngineFailure, engineFailure("empty url object!"))
 	}
 
 	// Check if the URL is valid
 	if !p.callAdaBoolFunction("ada_is_valid", urlObjPtr) {
-		adaFree := p.getFunction("ada_free")
-		if adaFree != nil {
-			adaFree.Call(p.ctx, uint64(urlObjPtr))
-		}
-		return nil, ErrInvalidUrl
+		p.freeUrlObject(urlObjPtr)
+		return fail(ReasonInvalidInput, ErrInvalidUrl)
 	}
 
-	url := &Url{parser: p, cpointer: urlObjPtr}
-	runtime.SetFinalizer(url, (*Url).ada_free)
-	return url, nil
+	return p.newUrl(urlObjPtr), nil
 }
 
-// NewWithBase parses the given strings into a URL with a base URL using the parser
+// NewWithBase parses the given strings into a URL with a base URL using the parser.
+// Failures are reported as a *ParseError, telling an invalid base apart from an invalid input.
 func (p *Parser) NewWithBase(urlstring, basestring string) (*Url, error) {
+	fail := func(reason string, err error) (*Url, error) {
+		return nil, newParseError("parse with base", urlstring, basestring, reason, err)
+	}
 	if len(urlstring) == 0 && len(basestring) == 0 {
-		return nil, ErrEmptyString
+		return fail(ReasonEmptyInput, ErrEmptyString)
 	}
 
 	// Write URL and base strings to WASM memory
 	urlPtr, err := p.writeStringToWasm(urlstring)
 	if err != nil {
-		return nil, err
+		return fail(ReasonEngineFailure, err)
 	}
 	defer p.wasmFree(urlPtr)
 
 	basePtr, err := p.writeStringToWasm(basestring)
 	if err != nil {
-		return nil, err
+		return fail(ReasonEngineFailure, err)
 	}
 	defer p.wasmFree(basePtr)
 
 	// Call ada_parse_with_base
 	parseFunc := p.getFunction("ada_parse_with_base")
 	if parseFunc == nil {
-		return nil, errors.New("ada_parse_with_base function not found")
+		return fail(ReasonEngineFailure, engineFailure("ada_parse_with_base function not found"))
 	}
 
-	results, err := parseFunc.Call(p.ctx, uint64(urlPtr), uint64(len(urlstring)), uint64(basePtr), uint64(len(basestring)))
+	results, err := p.call(parseFunc, uint64(urlPtr), uint64(len(urlstring)), uint64(basePtr), uint64(len(basestring)))
 	if err != nil {
-		return nil, err
+		return fail(ReasonEngineFailure, err)
 	}
 
 	urlObjPtr := uint32(results[0])
 	if urlObjPtr == 0 {
-		return nil, ErrInvalidUrl
+		return fail(ReasonEngineFailure, engineFailure("empty url object!"))
 	}
 
 	// Check if the URL is valid
 	if !p.callAdaBoolFunction("ada_is_valid", urlObjPtr) {
-		adaFree := p.getFunction("ada_free")
-		if adaFree != nil {
-			adaFree.Call(p.ctx, uint64(urlObjPtr))
+		p.freeUrlObject(urlObjPtr)
+		if !p.CanParse(basestring) {
+			return fail(ReasonInvalidBase, ErrInvalidUrl)
 		}
-		return nil, ErrInvalidUrl
+		return fail(ReasonInvalidInput, ErrInvalidUrl)
+	}
+
+	return p.newUrl(urlObjPtr), nil
+}
+
+// newUrl creates a Url owning the given ada_url object of the parser. The caller must hold its lock.
+func (p *Parser) newUrl(urlObjPtr uint32) *Url {
+	url := &Url{object: p.addObject(urlObjPtr, false)}
+	url.cleanup = runtime.AddCleanup(url, (*object).freeUrl, url.object)
+	return url
+}
+
+// freeUrlObject frees an ada_url object that no Url was created for
+func (p *Parser) freeUrlObject(urlObjPtr uint32) {
+	if adaFree := p.getFunction("ada_free"); adaFree != nil {
+		p.call(adaFree, uint64(urlObjPtr))
+	}
+}
+
+// CanParse checks if the given string parses as a valid URL using the parser
+func (p *Parser) CanParse(urlstring string) bool {
+	fn := p.getFunction("ada_can_parse")
+	if fn == nil {
+		return false
+	}
+
+	args, release, err := p.writeStringArgs(urlstring)
+	if err != nil {
+		return false
+	}
+	defer release()
+
+	results, err := p.call(fn, args...)
+	if err != nil {
+		return false
+	}
+
+	return results[0] != 0
+}
+
+// CanParseWithBase checks if the given string parses as a valid URL against the base using the parser
+func (p *Parser) CanParseWithBase(urlstring, basestring string) bool {
+	fn := p.getFunction("ada_can_parse_with_base")
+	if fn == nil {
+		return false
 	}
 
-	url := &Url{parser: p, cpointer: urlObjPtr}
-	runtime.SetFinalizer(url, (*Url).ada_free)
-	return url, nil
+	args, release, err := p.writeStringArgs(urlstring, basestring)
+	if err != nil {
+		return false
+	}
+	defer release()
+
+	results, err := p.call(fn, args...)
+	if err != nil {
+		return false
+	}
+
+	return results[0] != 0
 }
 
 // Free manually frees the URL object
 func (u *Url) Free() {
-	runtime.SetFinalizer(u, nil)
-	u.ada_free()
-	// Return parser to pool
-	if u.parser != nil {
-		parserPool.Put(u.parser)
-		u.parser = nil
+	u.cleanup.Stop()
+	u.guard.enter()
+	defer u.guard.exit()
+	u.object.freeUrl()
+}
+
+// freeUrl frees the ada_url object in WASM memory, once its Url was freed or collected
+func (o *object) freeUrl() {
+	if o.home.Load() == nil {
+		return
+	}
+
+	o.lock()
+	if adaFree := o.parser.getFunction("ada_free"); adaFree != nil {
+		o.parser.call(adaFree, uint64(o.cpointer))
+	}
+	p := o.parser
+	p.detach(o)
+	o.unlock()
+	o.home.Store(nil)
+	o.parser = nil
+	o.cpointer = 0
+
+	// Return an exclusively owned parser to the pool once no clone is using it
+	p.release()
+}
+
+// Clone returns an independent copy of the URL backed by ada_copy.
+// The copy lives in the WASM instance of the original; either of them may be freed first.
+func (u *Url) Clone() (*Url, error) {
+	u.lock()
+	defer u.unlock()
+	fn := u.parser.getFunction("ada_copy")
+	if fn == nil {
+		return nil, engineFailure("ada_copy function not found")
+	}
+
+	results, err := u.parser.call(fn, uint64(u.cpointer))
+	if err != nil {
+		return nil, err
 	}
+
+	urlObjPtr := uint32(results[0])
+	if urlObjPtr == 0 {
+		return nil, engineFailure("empty url object!")
+	}
+
+	return u.parser.newUrl(urlObjPtr), nil
 }
 
 // Valid checks if the URL is valid
 func (u *Url) Valid() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_is_valid", u.cpointer)
 }
 
 // HasCredentials checks if the URL has credentials
 func (u *Url) HasCredentials() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_has_credentials", u.cpointer)
 }
 
 // HasEmptyHostname checks if the URL has an empty hostname
 func (u *Url) HasEmptyHostname() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_has_empty_hostname", u.cpointer)
 }
 
 // HasHostname checks if the URL has a hostname
 func (u *Url) HasHostname() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_has_hostname", u.cpointer)
 }
 
 // HasNonEmptyUsername checks if the URL has a non-empty username
 func (u *Url) HasNonEmptyUsername() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_has_non_empty_username", u.cpointer)
 }
 
 // HasNonEmptyPassword checks if the URL has a non-empty password
 func (u *Url) HasNonEmptyPassword() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_has_non_empty_password", u.cpointer)
 }
 
 // HasPort checks if the URL has a port
 func (u *Url) HasPort() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_has_port", u.cpointer)
 }
 
 // HasPassword checks if the URL has a password
 func (u *Url) HasPassword() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_has_password", u.cpointer)
 }
 
 // HasHash checks if the URL has a hash
 func (u *Url) HasHash() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_has_hash", u.cpointer)
 }
 
 // HasSearch checks if the URL has a search/query string
 func (u *Url) HasSearch() bool {
+	u.lock()
+	defer u.unlock()
 	return u.parser.callAdaBoolFunction("ada_has_search", u.cpointer)
 }
 
 // Href returns the full URL string
 func (u *Url) Href() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_href")
 	if fn == nil {
 		return ""
@@ -425,6 +727,8 @@ func (u *Url) Href() string {
 
 // Username returns the username
 func (u *Url) Username() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_username")
 	if fn == nil {
 		return ""
@@ -435,6 +739,8 @@ func (u *Url) Username() string {
 
 // Password returns the password
 func (u *Url) Password() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_password")
 	if fn == nil {
 		return ""
@@ -445,6 +751,8 @@ func (u *Url) Password() string {
 
 // Port returns the port
 func (u *Url) Port() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_port")
 	if fn == nil {
 		return ""
@@ -455,6 +763,8 @@ func (u *Url) Port() string {
 
 // Hash returns the hash/fragment
 func (u *Url) Hash() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_hash")
 	if fn == nil {
 		return ""
@@ -465,6 +775,8 @@ func (u *Url) Hash() string {
 
 // Host returns the host (hostname + port)
 func (u *Url) Host() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_host")
 	if fn == nil {
 		return ""
@@ -475,6 +787,8 @@ func (u *Url) Host() string {
 
 // Hostname returns the hostname
 func (u *Url) Hostname() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_hostname")
 	if fn == nil {
 		return ""
@@ -485,6 +799,8 @@ func (u *Url) Hostname() string {
 
 // Pathname returns the pathname
 func (u *Url) Pathname() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_pathname")
 	if fn == nil {
 		return ""
@@ -495,6 +811,8 @@ func (u *Url) Pathname() string {
 
 // Search returns the search/query string
 func (u *Url) Search() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_search")
 	if fn == nil {
 		return ""
@@ -505,6 +823,8 @@ func (u *Url) Search() string {
 
 // Protocol returns the protocol/scheme
 func (u *Url) Protocol() string {
+	u.lock()
+	defer u.unlock()
 	fn := u.parser.getFunction("ada_get_protocol")
 	if fn == nil {
 		return ""
@@ -513,20 +833,37 @@ func (u *Url) Protocol() string {
 	return result
 }
 
-// Helper function to call setter functions that return bool
-func (u *Url) callSetterBool(funcName, value string) bool {
+// Origin returns the serialized origin ("null" for opaque origins)
+func (u *Url) Origin() string {
+	u.lock()
+	defer u.unlock()
+	fn := u.parser.getFunction("ada_get_origin")
+	if fn == nil {
+		return ""
+	}
+	result, _ := u.parser.readAdaOwnedString(fn, uint64(u.cpointer))
+	return result
+}
+
+// Helper function to call a setter function, reporting WASM failures
+func (u *Url) callSetter(funcName, value string) ([]uint64, error) {
 	fn := u.parser.getFunction(funcName)
 	if fn == nil {
-		return false
+		return nil, engineFailure(funcName + " function not found")
 	}
 
 	valuePtr, err := u.parser.writeStringToWasm(value)
 	if err != nil {
-		return false
+		return nil, err
 	}
 	defer u.parser.wasmFree(valuePtr)
 
-	results, err := fn.Call(u.parser.ctx, uint64(u.cpointer), uint64(valuePtr), uint64(len(value)))
+	return u.parser.call(fn, uint64(u.cpointer), uint64(valuePtr), uint64(len(value)))
+}
+
+// Helper function to call setter functions that return bool
+func (u *Url) callSetterBool(funcName, value string) bool {
+	results, err := u.callSetter(funcName, value)
 	if err != nil {
 		return false
 	}
@@ -536,66 +873,96 @@ func (u *Url) callSetterBool(funcName, value string) bool {
 
 // Helper function to call setter functions that return void
 func (u *Url) callSetterVoid(funcName, value string) {
-	fn := u.parser.getFunction(funcName)
-	if fn == nil {
-		return
-	}
-
-	valuePtr, err := u.parser.writeStringToWasm(value)
-	if err != nil {
-		return
-	}
-	defer u.parser.wasmFree(valuePtr)
-
-	fn.Call(u.parser.ctx, uint64(u.cpointer), uint64(valuePtr), uint64(len(value)))
+	u.callSetter(funcName, value)
 }
 
 // SetHref sets the full URL
 func (u *Url) SetHref(s string) bool {
+	u.lock()
+	defer u.unlock()
 	return u.callSetterBool("ada_set_href", s)
 }
 
 // SetHost sets the host
 func (u *Url) SetHost(s string) bool {
+	u.lock()
+	defer u.unlock()
 	return u.callSetterBool("ada_set_host", s)
 }
 
 // SetHostname sets the hostname
 func (u *Url) SetHostname(s string) bool {
+	u.lock()
+	defer u.unlock()
 	return u.callSetterBool("ada_set_hostname", s)
 }
 
 // SetProtocol sets the protocol
 func (u *Url) SetProtocol(s string) bool {
+	u.lock()
+	defer u.unlock()
 	return u.callSetterBool("ada_set_protocol", s)
 }
 
 // SetUsername sets the username
 func (u *Url) SetUsername(s string) bool {
+	u.lock()
+	defer u.unlock()
 	return u.callSetterBool("ada_set_username", s)
 }
 
 // SetPassword sets the password
 func (u *Url) SetPassword(s string) bool {
+	u.lock()
+	defer u.unlock()
 	return u.callSetterBool("ada_set_password", s)
 }
 
 // SetPort sets the port
 func (u *Url) SetPort(s string) bool {
+	u.lock()
+	defer u.unlock()
 	return u.callSetterBool("ada_set_port", s)
 }
 
 // SetPathname sets the pathname
 func (u *Url) SetPathname(s string) bool {
+	u.lock()
+	defer u.unlock()
 	return u.callSetterBool("ada_set_pathname", s)
 }
 
 // SetSearch sets the search/query string
 func (u *Url) SetSearch(s string) {
+	u.lock()
+	defer u.unlock()
 	u.callSetterVoid("ada_set_search", s)
 }
 
 // SetHash sets the hash/fragment
 func (u *Url) SetHash(s string) {
+	u.lock()
+	defer u.unlock()
 	u.callSetterVoid("ada_set_hash", s)
 }
+
+// ClearHash removes the hash/fragment, including the "#" delimiter
+func (u *Url) ClearHash() {
+	u.lock()
+	defer u.unlock()
+	u.parser.callAdaVoidFunction("ada_clear_hash", u.cpointer)
+}
+
+// ClearPort removes the explicit port
+func (u *Url) ClearPort() {
+	u.lock()
+	defer u.unlock()
+	u.parser.callAdaVoidFunction("ada_clear_port", u.cpointer)
+}
+
+// ClearSearch removes the search/query string, including the "?" delimiter
+func (u *Url) ClearSearch() {
+	u.lock()
+	defer u.unlock()
+	u.parser.callAdaVoidFunction("ada_clear_search", u.cpointer)
+}
@@ -0,0 +1,13 @@
+//go:build !goadawasm_debug
+
+package goadawasm
+
+// usageGuard detects a Url used from several goroutines at once.
+// Detection is only compiled in with the goadawasm_debug build tag; see guard_debug.go.
+type usageGuard struct{}
+
+// enter marks the start of a call
+func (*usageGuard) enter() {}
+
+// exit marks the end of a call started with enter
+func (*usageGuard) exit() {}
@@ -0,0 +1,65 @@
+//go:build goadawasm_debug
+
+package goadawasm
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// usageGuard detects a Url used from several goroutines at once, which races with Free
+// and interleaves changes. It tracks the goroutine currently inside a call, allowing
+// nested calls from that same goroutine.
+type usageGuard struct {
+	owner atomic.Int64 // ID of the goroutine inside a call, 0 if none
+	depth int          // Nesting level of the owner, only touched by the owner
+}
+
+// enter marks the start of a call
+func (g *usageGuard) enter() {
+	gid := goroutineID()
+	if !g.owner.CompareAndSwap(0, gid) && g.owner.Load() != gid {
+		panic("goadawasm: " + callerName() + " called while goroutine " + strconv.FormatInt(g.owner.Load(), 10) +
+			" is using the same Url; Url is not concurrency-safe, use SyncUrl instead")
+	}
+	g.depth++
+}
+
+// exit marks the end of a call started with enter
+func (g *usageGuard) exit() {
+	g.depth--
+	if g.depth == 0 {
+		g.owner.Store(0)
+	}
+}
+
+// goroutineID parses the ID of the current goroutine from its stack header
+func goroutineID() int64 {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	if i := bytes.IndexByte(header, ' '); i >= 0 {
+		header = header[:i]
+	}
+	id, _ := strconv.ParseInt(string(header), 10, 64)
+	return id
+}
+
+// callerName returns the name of the exported Url method that entered the guard
+func callerName() string {
+	var pcs [8]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		name := frame.Function
+		if i := strings.LastIndex(name, "."); i >= 0 && name[i+1] >= 'A' && name[i+1] <= 'Z' {
+			return "Url." + name[i+1:]
+		}
+		if !more {
+			return "Url method"
+		}
+	}
+}
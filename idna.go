@@ -0,0 +1,116 @@
+package goadawasm
+
+import "errors"
+
+var ErrInvalidDomain = errors.New("invalid domain")
+
+// ToASCII converts a domain to its ASCII (punycode) form using the same UTS #46
+// processing and forbidden code point checks that the URL parser applies to hostnames.
+// Unlike the URL parser, it does not percent-decode the input or parse IP addresses.
+func ToASCII(domain string) (string, error) {
+	return defaultEngine().ToASCII(domain)
+}
+
+// ToUnicode converts a domain to its Unicode form, decoding punycode labels.
+// Invalid domains and labels fail with ErrInvalidDomain, as with ToASCII.
+func ToUnicode(domain string) (string, error) {
+	return defaultEngine().ToUnicode(domain)
+}
+
+// ToASCII converts a domain to its ASCII (punycode) form using a parser of the engine
+func (e *Engine) ToASCII(domain string) (string, error) {
+	parser, err := e.acquireParser()
+	if err != nil {
+		return "", err
+	}
+	defer e.releaseParser(parser)
+
+	return parser.ToASCII(domain)
+}
+
+// ToUnicode converts a domain to its Unicode form using a parser of the engine
+func (e *Engine) ToUnicode(domain string) (string, error) {
+	parser, err := e.acquireParser()
+	if err != nil {
+		return "", err
+	}
+	defer e.releaseParser(parser)
+
+	return parser.ToUnicode(domain)
+}
+
+// ToASCII converts a domain to its ASCII (punycode) form using the parser
+func (p *Parser) ToASCII(domain string) (string, error) {
+	result, err := p.callIdnaFunction("ada_idna_to_ascii", domain)
+	if err != nil {
+		return "", err
+	}
+	if containsForbiddenDomainCodePoint(result) {
+		return "", ErrInvalidDomain
+	}
+	return result, nil
+}
+
+// ToUnicode converts a domain to its Unicode form using the parser.
+// Domains that ToASCII rejects, including punycode labels decoding to disallowed code points,
+// and results that do not convert back to the same ASCII form fail with ErrInvalidDomain.
+func (p *Parser) ToUnicode(domain string) (string, error) {
+	ascii, err := p.ToASCII(domain)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := p.callIdnaFunction("ada_idna_to_unicode", domain)
+	if err != nil {
+		return "", err
+	}
+
+	// ada decodes punycode without validating the labels it produces
+	if roundTrip, err := p.ToASCII(result); err != nil || roundTrip != ascii {
+		return "", ErrInvalidDomain
+	}
+	return result, nil
+}
+
+// Helper function to call an IDNA function, which reports failures as an empty result
+func (p *Parser) callIdnaFunction(funcName, domain string) (string, error) {
+	if len(domain) == 0 {
+		return "", ErrEmptyString
+	}
+
+	fn := p.getFunction(funcName)
+	if fn == nil {
+		return "", engineFailure(funcName + " function not found")
+	}
+
+	args, release, err := p.writeStringArgs(domain)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	result, err := p.readAdaOwnedString(fn, args...)
+	if err != nil {
+		return "", err
+	}
+	if len(result) == 0 {
+		return "", ErrInvalidDomain
+	}
+
+	return result, nil
+}
+
+// Helper function to check for forbidden domain code points, which the URL parser rejects after IDNA processing
+func containsForbiddenDomainCodePoint(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= 0x20 || c == 0x7f { // C0 controls, space and delete
+			return true
+		}
+		switch c {
+		case '#', '%', '/', ':', '<', '>', '?', '@', '[', '\\', ']', '^', '|':
+			return true
+		}
+	}
+	return false
+}
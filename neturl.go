@@ -0,0 +1,148 @@
+package goadawasm
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrNotRepresentable is matched by errors.Is when a URL cannot be converted to a net/url.URL losslessly
+var ErrNotRepresentable = errors.New("url not representable as net/url.URL")
+
+// netURLError reports a URL that a net/url.URL cannot hold without changing its serialization
+type netURLError struct {
+	href   string
+	reason string
+}
+
+func (e *netURLError) Error() string {
+	return "convert " + strconv.Quote(truncateInput(e.href)) + " to net/url: " + e.reason
+}
+
+func (e *netURLError) Unwrap() error {
+	return ErrNotRepresentable
+}
+
+// ToNetURL converts the URL to a net/url.URL whose String() is exactly Href.
+// Percent-encoded components are decoded into Path, Fragment, User and Host, keeping the
+// original encoding in RawPath, RawFragment and RawQuery. URLs with an opaque path, such as
+// "mailto:" URLs, fill Opaque; URLs without a host and with a path starting with "/" set OmitHost.
+//
+// WHATWG and RFC 3986 disagree on some serializations, e.g. WHATWG keeps "|" unescaped in paths,
+// keeps an empty fragment ("#") and accepts malformed percent-encodings, which net/url escapes, drops
+// or rejects. Such URLs fail with an error matching ErrNotRepresentable; use url.Parse(Href()) if
+// an approximate conversion is good enough.
+func (u *Url) ToNetURL() (*url.URL, error) {
+	components, err := u.Components()
+	if err != nil {
+		return nil, err
+	}
+	return componentsToNetURL(components)
+}
+
+// ToNetURL converts the record to a net/url.URL whose String() is exactly Href. See Url.ToNetURL.
+func (r URLRecord) ToNetURL() (*url.URL, error) {
+	return componentsToNetURL(r.components)
+}
+
+// componentsToNetURL maps WHATWG components onto the fields of a net/url.URL
+func componentsToNetURL(c Components) (*url.URL, error) {
+	href := c.Href
+	fail := func(reason string) (*url.URL, error) {
+		return nil, &netURLError{href: href, reason: reason}
+	}
+
+	result := &url.URL{Scheme: strings.TrimSuffix(c.Protocol, ":")}
+
+	rest := strings.TrimPrefix(href, c.Protocol)
+	switch {
+	case strings.HasPrefix(rest, "//"):
+		if c.Username != "" || c.Password != "" {
+			username, err := url.PathUnescape(c.Username)
+			if err != nil {
+				return fail("invalid percent-encoding in username")
+			}
+			if c.Password != "" {
+				password, err := url.PathUnescape(c.Password)
+				if err != nil {
+					return fail("invalid percent-encoding in password")
+				}
+				result.User = url.UserPassword(username, password)
+			} else {
+				result.User = url.User(username)
+			}
+		}
+
+		// Only opaque hosts of non-special URLs may hold percent-encoded bytes
+		host, err := url.PathUnescape(c.Host)
+		if err != nil {
+			return fail("invalid percent-encoding in host")
+		}
+		result.Host = host
+		if !setNetURLPath(result, c.Pathname) {
+			return fail("invalid percent-encoding in path")
+		}
+	case strings.HasPrefix(c.Pathname, "/"):
+		// A path without a host, e.g. "foo:/bar"
+		result.OmitHost = true
+		if !setNetURLPath(result, c.Pathname) {
+			return fail("invalid percent-encoding in path")
+		}
+	default:
+		// An opaque path, e.g. "mailto:user@example.com"
+		result.Opaque = c.Pathname
+	}
+
+	// The getter returns an empty search for a lone "?", which only shows up in href.
+	// A "?" before the fragment always starts the query, as it is percent-encoded anywhere else.
+	result.RawQuery = strings.TrimPrefix(c.Search, "?")
+	if beforeHash, _, _ := strings.Cut(href, "#"); c.Search == "" && strings.Contains(beforeHash, "?") {
+		result.ForceQuery = true
+	}
+
+	if c.Hash != "" {
+		rawFragment := strings.TrimPrefix(c.Hash, "#")
+		fragment, err := url.PathUnescape(rawFragment)
+		if err != nil {
+			return fail("invalid percent-encoding in fragment")
+		}
+		result.Fragment = fragment
+		if result.EscapedFragment() != rawFragment {
+			result.RawFragment = rawFragment
+		}
+	}
+
+	if s := result.String(); s != href {
+		return fail("net/url serializes it as " + strconv.Quote(truncateInput(s)))
+	}
+	return result, nil
+}
+
+// setNetURLPath sets the decoded path and, if net/url would encode it differently, the raw one
+func setNetURLPath(u *url.URL, pathname string) bool {
+	path, err := url.PathUnescape(pathname)
+	if err != nil {
+		return false
+	}
+	u.Path = path
+	if u.EscapedPath() != pathname {
+		u.RawPath = pathname
+	}
+	return true
+}
+
+// FromNetURL parses the serialization of a net/url.URL into a Url, which must be freed.
+// The WHATWG parser normalizes it on the way, e.g. lowercasing the host and dropping a default port,
+// and fails with a *ParseError for relative references, which have no base to resolve against.
+func FromNetURL(u *url.URL) (*Url, error) {
+	return defaultEngine().FromNetURL(u)
+}
+
+// FromNetURL parses the serialization of a net/url.URL into a Url using a parser of the engine
+func (e *Engine) FromNetURL(u *url.URL) (*Url, error) {
+	if u == nil {
+		return nil, newParseError("parse", "", "", ReasonEmptyInput, ErrEmptyString)
+	}
+	return e.New(u.String())
+}
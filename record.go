@@ -0,0 +1,201 @@
+package goadawasm
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// errZeroRecord reports a zero URLRecord where a URL is required
+var errZeroRecord = errors.New("zero URLRecord holds no URL")
+
+// URLRecord is an immutable snapshot of a parsed URL held entirely in Go memory.
+// Unlike Url it needs no Free and is safe to copy, compare with == and share between goroutines,
+// so it can be used as a struct field, map key or message payload. The zero value is an empty record.
+type URLRecord struct {
+	components Components
+	origin     string
+	hostType   HostType
+	schemeType SchemeType
+}
+
+// ParseValue parses the given string into a URLRecord, freeing the intermediate Url right away
+func ParseValue(urlstring string) (URLRecord, error) {
+	return defaultEngine().ParseValue(urlstring)
+}
+
+// ParseValueWithBase parses the given strings into a URLRecord with a base URL
+func ParseValueWithBase(urlstring, basestring string) (URLRecord, error) {
+	return defaultEngine().ParseValueWithBase(urlstring, basestring)
+}
+
+// ParseValue parses the given string into a URLRecord using a parser of the engine
+func (e *Engine) ParseValue(urlstring string) (URLRecord, error) {
+	url, err := e.New(urlstring)
+	if err != nil {
+		return URLRecord{}, err
+	}
+	defer url.Free()
+
+	return url.Detach()
+}
+
+// ParseValueWithBase parses the given strings into a URLRecord with a base URL using a parser of the engine
+func (e *Engine) ParseValueWithBase(urlstring, basestring string) (URLRecord, error) {
+	url, err := e.NewWithBase(urlstring, basestring)
+	if err != nil {
+		return URLRecord{}, err
+	}
+	defer url.Free()
+
+	return url.Detach()
+}
+
+// Detach returns a URLRecord snapshot of the URL's current state.
+// The URL itself stays valid and must still be freed.
+func (u *Url) Detach() (URLRecord, error) {
+	u.guard.enter()
+	defer u.guard.exit()
+	components, err := u.Components()
+	if err != nil {
+		return URLRecord{}, err
+	}
+
+	return URLRecord{
+		components: components,
+		origin:     u.Origin(),
+		hostType:   u.HostType(),
+		schemeType: u.SchemeType(),
+	}, nil
+}
+
+// Url parses the record back into a mutable Url, which must be freed
+func (r URLRecord) Url() (*Url, error) {
+	return New(r.components.Href)
+}
+
+// NewFromRecord parses the record back into a mutable Url using a parser of the engine
+func (e *Engine) NewFromRecord(r URLRecord) (*Url, error) {
+	return e.New(r.components.Href)
+}
+
+// IsZero checks if the record is the zero value rather than a parsed URL
+func (r URLRecord) IsZero() bool {
+	return r == URLRecord{}
+}
+
+// String returns the full URL string
+func (r URLRecord) String() string {
+	return r.components.Href
+}
+
+// Components returns all components of the URL
+func (r URLRecord) Components() Components {
+	return r.components
+}
+
+// Href returns the full URL string
+func (r URLRecord) Href() string {
+	return r.components.Href
+}
+
+// Protocol returns the protocol/scheme
+func (r URLRecord) Protocol() string {
+	return r.components.Protocol
+}
+
+// Username returns the username
+func (r URLRecord) Username() string {
+	return r.components.Username
+}
+
+// Password returns the password
+func (r URLRecord) Password() string {
+	return r.components.Password
+}
+
+// Host returns the host (hostname + port)
+func (r URLRecord) Host() string {
+	return r.components.Host
+}
+
+// Hostname returns the hostname
+func (r URLRecord) Hostname() string {
+	return r.components.Hostname
+}
+
+// Port returns the port
+func (r URLRecord) Port() string {
+	return r.components.Port
+}
+
+// Pathname returns the pathname
+func (r URLRecord) Pathname() string {
+	return r.components.Pathname
+}
+
+// Search returns the search/query string
+func (r URLRecord) Search() string {
+	return r.components.Search
+}
+
+// Hash returns the hash/fragment
+func (r URLRecord) Hash() string {
+	return r.components.Hash
+}
+
+// Origin returns the serialized origin ("null" for opaque origins)
+func (r URLRecord) Origin() string {
+	return r.origin
+}
+
+// HostType returns the kind of host of the URL
+func (r URLRecord) HostType() HostType {
+	return r.hostType
+}
+
+// SchemeType returns the kind of scheme of the URL
+func (r URLRecord) SchemeType() SchemeType {
+	return r.schemeType
+}
+
+// MarshalText returns the full URL string. The zero record holds no URL and fails to marshal;
+// use a *URLRecord for optional URLs.
+func (r URLRecord) MarshalText() ([]byte, error) {
+	if r.IsZero() {
+		return nil, errZeroRecord
+	}
+	return []byte(r.components.Href), nil
+}
+
+// UnmarshalText parses and normalizes the URL string into the record.
+// An invalid string, including an empty one, fails with a *ParseError naming it.
+func (r *URLRecord) UnmarshalText(text []byte) error {
+	record, err := ParseValue(string(text))
+	if err != nil {
+		return err
+	}
+	*r = record
+	return nil
+}
+
+// MarshalJSON returns the full URL string as a JSON string, failing for the zero record like MarshalText
+func (r URLRecord) MarshalJSON() ([]byte, error) {
+	if r.IsZero() {
+		return nil, errZeroRecord
+	}
+	return json.Marshal(r.components.Href)
+}
+
+// UnmarshalJSON parses and normalizes a JSON string into the record like UnmarshalText.
+// A JSON null leaves the record unchanged, so a nil *URLRecord stays nil.
+func (r *URLRecord) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return r.UnmarshalText([]byte(s))
+}
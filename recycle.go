@@ -0,0 +1,136 @@
+package goadawasm
+
+import "sync/atomic"
+
+// object is the part of a URL or search params that lives in a WASM instance.
+// Parsers list their objects so that a retired instance can move them to another one;
+// objects hold no reference back to their URL or search params, which stay collectable.
+type object struct {
+	home     atomic.Pointer[Parser] // Parser to lock, which changes when a retired instance moves the object
+	parser   *Parser                // Reference to the parser holding the object, only read with its lock held
+	cpointer uint32                 // Pointer to the ada_url or ada_url_search_params object in WASM memory
+	index    int                    // Position in the objects of the parser
+	params   bool                   // Whether the object is search params rather than a URL
+}
+
+// lock serializes a call with the other users of the WASM instance,
+// following the object if it was moved while waiting for the lock
+func (o *object) lock() {
+	for {
+		p := o.home.Load()
+		p.lock.Lock()
+		if o.home.Load() == p {
+			return
+		}
+		p.lock.Unlock()
+	}
+}
+
+// unlock ends a call started with lock
+func (o *object) unlock() {
+	o.parser.unlock()
+}
+
+// err reports whether the object is no longer usable; nil once it was freed
+func (o *object) err() error {
+	p := o.home.Load()
+	if p == nil {
+		return nil
+	}
+	return p.err()
+}
+
+// addObject creates an object for the given pointer of the parser. The caller must hold its lock.
+func (p *Parser) addObject(cpointer uint32, params bool) *object {
+	p.allocs.Add(1)
+	p.retain()
+	o := &object{parser: p, cpointer: cpointer, params: params}
+	o.home.Store(p)
+	p.attach(o)
+	return o
+}
+
+// attach lists the object in the parser. The caller must hold its lock.
+func (p *Parser) attach(o *object) {
+	o.index = len(p.objects)
+	p.objects = append(p.objects, o)
+}
+
+// detach removes the object from the parser in constant time. The caller must hold its lock.
+func (p *Parser) detach(o *object) {
+	last := len(p.objects) - 1
+	p.objects[o.index] = p.objects[last]
+	p.objects[o.index].index = o.index
+	p.objects[last] = nil
+	p.objects = p.objects[:last]
+}
+
+// moveObjects re-creates the objects of a retired parser in another parser of the engine from their
+// serialization, so that the retired instance can be closed. Objects that cannot be moved are left in place.
+// The caller must hold the lock.
+func (p *Parser) moveObjects() {
+	if len(p.objects) == 0 {
+		return
+	}
+	target, err := p.engine.acquireParser()
+	if err != nil {
+		return
+	}
+	defer p.engine.releaseParser(target)
+
+	// Iterate backwards, as moving an object swaps the last one into its place
+	for i := len(p.objects) - 1; i >= 0; i-- {
+		p.moveObject(p.objects[i], target)
+	}
+	target.floor = max(target.floor, uint64(target.module.Memory().Size()))
+}
+
+// moveObject re-creates the object in the target parser and points it there.
+// The caller must hold the locks of both parsers.
+func (p *Parser) moveObject(o *object, target *Parser) {
+	serialize, parse, free := "ada_get_href", "ada_parse", "ada_free"
+	if o.params {
+		serialize, parse, free = "ada_search_params_to_string", "ada_parse_search_params", "ada_free_search_params"
+	}
+
+	fn := p.getFunction(serialize)
+	if fn == nil {
+		return
+	}
+	var s string
+	var err error
+	if o.params {
+		s, err = p.readAdaOwnedString(fn, uint64(o.cpointer))
+	} else {
+		s, err = p.readAdaString(fn, o.cpointer)
+	}
+	if err != nil {
+		return
+	}
+
+	parseFunc := target.getFunction(parse)
+	if parseFunc == nil {
+		return
+	}
+	args, release, err := target.writeStringArgs(s)
+	if err != nil {
+		return
+	}
+	results, err := target.call(parseFunc, args...)
+	release()
+	if err != nil || uint32(results[0]) == 0 {
+		return
+	}
+
+	if freeFunc := p.getFunction(free); freeFunc != nil {
+		p.call(freeFunc, uint64(o.cpointer))
+	}
+	p.detach(o)
+	p.refs.Add(-1)
+
+	// A moved object does not count as an allocation of the target
+	target.retain()
+	target.attach(o)
+	o.parser, o.cpointer = target, uint32(results[0])
+	o.home.Store(target)
+}
@@ -0,0 +1,414 @@
+package goadawasm
+
+import "runtime"
+
+// SearchParams represents a WHATWG URLSearchParams list backed by Ada WASM implementation
+type SearchParams struct {
+	*object // The ada_url_search_params object in WASM memory
+}
+
+// SearchParam is a single name-value pair of SearchParams
+type SearchParam struct {
+	Key   string
+	Value string
+}
+
+// ParseSearchParams parses the given query string into SearchParams.
+// A leading "?" is ignored, as with the URLSearchParams constructor.
+func ParseSearchParams(query string) (*SearchParams, error) {
+	return defaultEngine().ParseSearchParams(query)
+}
+
+// ParseSearchParams parses the given query string into SearchParams using a parser of the engine
+func (e *Engine) ParseSearchParams(query string) (*SearchParams, error) {
+	parser, err := e.acquireParser()
+	if err != nil {
+		return nil, err
+	}
+	defer e.releaseParser(parser)
+
+	return parser.ParseSearchParams(query)
+}
+
+// ParseSearchParams parses the given query string into SearchParams using the parser
+func (p *Parser) ParseSearchParams(query string) (*SearchParams, error) {
+	queryPtr, err := p.writeStringToWasm(query)
+	if err != nil {
+		return nil, err
+	}
+	defer p.wasmFree(queryPtr)
+
+	parseFunc := p.getFunction("ada_parse_search_params")
+	if parseFunc == nil {
+		return nil, engineFailure("ada_parse_search_params function not found")
+	}
+
+	results, err := p.call(parseFunc, uint64(queryPtr), uint64(len(query)))
+	if err != nil {
+		return nil, err
+	}
+
+	paramsPtr := uint32(results[0])
+	if paramsPtr == 0 {
+		return nil, engineFailure("empty search params object!")
+	}
+
+	params := &SearchParams{object: p.addObject(paramsPtr, true)}
+	runtime.SetFinalizer(params, (*SearchParams).Free)
+	return params, nil
+}
+
+// SearchParams parses the search/query string of the URL into SearchParams.
+// The result is independent of the URL; use SetSearchParams to write changes back.
+func (u *Url) SearchParams() (*SearchParams, error) {
+	u.guard.enter()
+	defer u.guard.exit()
+	return u.home.Load().engine.ParseSearchParams(u.Search())
+}
+
+// SetSearchParams replaces the search/query string of the URL with the serialized params
+func (u *Url) SetSearchParams(params *SearchParams) {
+	u.guard.enter()
+	defer u.guard.exit()
+	u.SetSearch(params.String())
+}
+
+// ada_free frees the search params object in WASM memory
+func (sp *SearchParams) ada_free() {
+	if sp.cpointer != 0 {
+		adaFree := sp.parser.getFunction("ada_free_search_params")
+		if adaFree != nil {
+			sp.parser.call(adaFree, uint64(sp.cpointer))
+		}
+		sp.cpointer = 0
+	}
+}
+
+// Free manually frees the search params object
+func (sp *SearchParams) Free() {
+	runtime.SetFinalizer(sp, nil)
+	if sp.home.Load() == nil {
+		return
+	}
+
+	sp.lock()
+	sp.ada_free()
+	p := sp.parser
+	p.detach(sp.object)
+	sp.unlock()
+	sp.home.Store(nil)
+	sp.parser = nil
+
+	// Return an exclusively owned parser to the pool
+	p.release()
+}
+
+// Helper function to call a search params function with string arguments
+func (sp *SearchParams) call(funcName string, strs ...string) ([]uint64, error) {
+	fn := sp.parser.getFunction(funcName)
+	if fn == nil {
+		return nil, engineFailure(funcName + " function not found")
+	}
+
+	args, release, err := sp.parser.writeStringArgs(strs...)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return sp.parser.call(fn, append([]uint64{uint64(sp.cpointer)}, args...)...)
+}
+
+// Err returns an error matching ErrEngineFailure once the search params are no longer usable. See Url.Err.
+func (sp *SearchParams) Err() error {
+	return sp.object.err()
+}
+
+// Size returns the number of name-value pairs
+func (sp *SearchParams) Size() int {
+	sp.lock()
+	defer sp.unlock()
+	results, err := sp.call("ada_search_params_size")
+	if err != nil {
+		return 0
+	}
+	return int(uint32(results[0]))
+}
+
+// Append appends a new name-value pair
+func (sp *SearchParams) Append(key, value string) {
+	sp.lock()
+	defer sp.unlock()
+	sp.call("ada_search_params_append", key, value)
+}
+
+// Set sets the value of the first pair with the given name and removes all others,
+// or appends a new pair if there is none
+func (sp *SearchParams) Set(key, value string) {
+	sp.lock()
+	defer sp.unlock()
+	sp.call("ada_search_params_set", key, value)
+}
+
+// Delete removes all pairs with the given name
+func (sp *SearchParams) Delete(key string) {
+	sp.lock()
+	defer sp.unlock()
+	sp.call("ada_search_params_remove", key)
+}
+
+// DeleteValue removes all pairs with the given name and value
+func (sp *SearchParams) DeleteValue(key, value string) {
+	sp.lock()
+	defer sp.unlock()
+	sp.call("ada_search_params_remove_value", key, value)
+}
+
+// Has checks if a pair with the given name exists
+func (sp *SearchParams) Has(key string) bool {
+	sp.lock()
+	defer sp.unlock()
+	results, err := sp.call("ada_search_params_has", key)
+	if err != nil {
+		return false
+	}
+	return results[0] != 0
+}
+
+// HasValue checks if a pair with the given name and value exists
+func (sp *SearchParams) HasValue(key, value string) bool {
+	sp.lock()
+	defer sp.unlock()
+	results, err := sp.call("ada_search_params_has_value", key, value)
+	if err != nil {
+		return false
+	}
+	return results[0] != 0
+}
+
+// Get returns the value of the first pair with the given name.
+// The boolean result reports whether such a pair exists.
+func (sp *SearchParams) Get(key string) (string, bool) {
+	sp.lock()
+	defer sp.unlock()
+	fn := sp.parser.getFunction("ada_search_params_get")
+	if fn == nil {
+		return "", false
+	}
+
+	args, release, err := sp.parser.writeStringArgs(key)
+	if err != nil {
+		return "", false
+	}
+	defer release()
+
+	bufferPtr, length, err := sp.parser.callAdaStringFunction(fn, append([]uint64{uint64(sp.cpointer)}, args...)...)
+	if err != nil || bufferPtr == 0 {
+		return "", false
+	}
+
+	value, err := sp.parser.readWasmString(bufferPtr, length)
+	if err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+// GetAll returns the values of all pairs with the given name
+func (sp *SearchParams) GetAll(key string) []string {
+	sp.lock()
+	defer sp.unlock()
+	results, err := sp.call("ada_search_params_get_all", key)
+	if err != nil {
+		return nil
+	}
+
+	stringsPtr := uint32(results[0])
+	if stringsPtr == 0 {
+		return nil
+	}
+	defer func() {
+		if freeStrings := sp.parser.getFunction("ada_free_strings"); freeStrings != nil {
+			sp.parser.call(freeStrings, uint64(stringsPtr))
+		}
+	}()
+
+	sizeFunc := sp.parser.getFunction("ada_strings_size")
+	getFunc := sp.parser.getFunction("ada_strings_get")
+	if sizeFunc == nil || getFunc == nil {
+		return nil
+	}
+
+	sizeResults, err := sp.parser.call(sizeFunc, uint64(stringsPtr))
+	if err != nil {
+		return nil
+	}
+
+	size := uint32(sizeResults[0])
+	values := make([]string, 0, size)
+	for i := uint32(0); i < size; i++ {
+		bufferPtr, length, err := sp.parser.callAdaStringFunction(getFunc, uint64(stringsPtr), uint64(i))
+		if err != nil {
+			return nil
+		}
+		value, err := sp.parser.readWasmString(bufferPtr, length)
+		if err != nil {
+			return nil
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// Sort sorts all pairs by their names, preserving the relative order of pairs with equal names
+func (sp *SearchParams) Sort() {
+	sp.lock()
+	defer sp.unlock()
+	sp.call("ada_search_params_sort")
+}
+
+// Reset replaces all pairs with the ones parsed from the given query string
+func (sp *SearchParams) Reset(query string) {
+	sp.lock()
+	defer sp.unlock()
+	sp.call("ada_search_params_reset", query)
+}
+
+// String returns the application/x-www-form-urlencoded serialization, without a leading "?"
+func (sp *SearchParams) String() string {
+	sp.lock()
+	defer sp.unlock()
+	fn := sp.parser.getFunction("ada_search_params_to_string")
+	if fn == nil {
+		return ""
+	}
+	result, _ := sp.parser.readAdaOwnedString(fn, uint64(sp.cpointer))
+	return result
+}
+
+// Helper function to drain an ada keys/values iterator into a slice
+func (sp *SearchParams) collectStrings(getIter, hasNext, next, freeIter string) []string {
+	iterPtr, ok := sp.newIterator(getIter)
+	if !ok {
+		return nil
+	}
+	defer sp.freeIterator(freeIter, iterPtr)
+
+	hasNextFunc := sp.parser.getFunction(hasNext)
+	nextFunc := sp.parser.getFunction(next)
+	if hasNextFunc == nil || nextFunc == nil {
+		return nil
+	}
+
+	var values []string
+	for {
+		results, err := sp.parser.call(hasNextFunc, uint64(iterPtr))
+		if err != nil || results[0] == 0 {
+			break
+		}
+		bufferPtr, length, err := sp.parser.callAdaStringFunction(nextFunc, uint64(iterPtr))
+		if err != nil {
+			break
+		}
+		value, err := sp.parser.readWasmString(bufferPtr, length)
+		if err != nil {
+			break
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// Helper function to create an ada search params iterator
+func (sp *SearchParams) newIterator(getIter string) (uint32, bool) {
+	results, err := sp.call(getIter)
+	if err != nil || results[0] == 0 {
+		return 0, false
+	}
+	return uint32(results[0]), true
+}
+
+// Helper function to free an ada search params iterator
+func (sp *SearchParams) freeIterator(freeIter string, iterPtr uint32) {
+	if fn := sp.parser.getFunction(freeIter); fn != nil {
+		sp.parser.call(fn, uint64(iterPtr))
+	}
+}
+
+// Keys returns the names of all pairs, in order
+func (sp *SearchParams) Keys() []string {
+	sp.lock()
+	defer sp.unlock()
+	return sp.collectStrings(
+		"ada_search_params_get_keys",
+		"ada_search_params_keys_iter_has_next",
+		"ada_search_params_keys_iter_next",
+		"ada_free_search_params_keys_iter",
+	)
+}
+
+// Values returns the values of all pairs, in order
+func (sp *SearchParams) Values() []string {
+	sp.lock()
+	defer sp.unlock()
+	return sp.collectStrings(
+		"ada_search_params_get_values",
+		"ada_search_params_values_iter_has_next",
+		"ada_search_params_values_iter_next",
+		"ada_free_search_params_values_iter",
+	)
+}
+
+// Entries returns all name-value pairs, in order
+func (sp *SearchParams) Entries() []SearchParam {
+	sp.lock()
+	defer sp.unlock()
+	iterPtr, ok := sp.newIterator("ada_search_params_get_entries")
+	if !ok {
+		return nil
+	}
+	defer sp.freeIterator("ada_free_search_params_entries_iter", iterPtr)
+
+	hasNextFunc := sp.parser.getFunction("ada_search_params_entries_iter_has_next")
+	nextFunc := sp.parser.getFunction("ada_search_params_entries_iter_next")
+	if hasNextFunc == nil || nextFunc == nil {
+		return nil
+	}
+
+	// Allocate memory for the ada_string_pair result struct
+	resultPtr, err := sp.parser.wasmMalloc(16) // 16 bytes for ada_string_pair struct
+	if err != nil {
+		return nil
+	}
+	defer sp.parser.wasmFree(resultPtr)
+
+	var entries []SearchParam
+	for {
+		results, err := sp.parser.call(hasNextFunc, uint64(iterPtr))
+		if err != nil || results[0] == 0 {
+			break
+		}
+		if _, err := sp.parser.call(nextFunc, uint64(resultPtr), uint64(iterPtr)); err != nil {
+			break
+		}
+		resultBytes, ok := sp.parser.module.Memory().Read(resultPtr, 16)
+		if !ok {
+			break
+		}
+		keyPtr, keyLength := decodeAdaString(resultBytes[0:8])
+		valuePtr, valueLength := decodeAdaString(resultBytes[8:16])
+		key, err := sp.parser.readWasmString(keyPtr, keyLength)
+		if err != nil {
+			break
+		}
+		value, err := sp.parser.readWasmString(valuePtr, valueLength)
+		if err != nil {
+			break
+		}
+		entries = append(entries, SearchParam{Key: key, Value: value})
+	}
+
+	return entries
+}
@@ -0,0 +1,159 @@
+package goadawasm
+
+import "strings"
+
+// Helper function to call a bool setter, explaining why the value was not applied.
+// applicable reports whether the URL can have the component at all; nil means always.
+func (u *Url) setChecked(op, funcName, value string, applicable func() bool) error {
+	if applicable != nil && !applicable() {
+		return newSetterError(op, value, ReasonNotApplicable, ErrNotApplicable)
+	}
+
+	results, err := u.callSetter(funcName, value)
+	if err != nil {
+		return newSetterError(op, value, ReasonEngineFailure, err)
+	}
+	if results[0] == 0 {
+		return newSetterError(op, value, ReasonValueRejected, ErrValueRejected)
+	}
+	return nil
+}
+
+// Helper function to call a void setter, which can only fail in the engine
+func (u *Url) setVoidChecked(op, funcName, value string) error {
+	if _, err := u.callSetter(funcName, value); err != nil {
+		return newSetterError(op, value, ReasonEngineFailure, err)
+	}
+	return nil
+}
+
+// hasOpaquePath checks if the URL has an opaque path (e.g. "mailto:" URLs),
+// which leaves no room for a host or a hierarchical pathname
+func (u *Url) hasOpaquePath() bool {
+	if u.parser.callAdaBoolFunction("ada_has_hostname", u.cpointer) {
+		return false
+	}
+	fn := u.parser.getFunction("ada_get_pathname")
+	if fn == nil {
+		return false
+	}
+	pathname, err := u.parser.readAdaString(fn, u.cpointer)
+	if err != nil {
+		return false
+	}
+	return !strings.HasPrefix(pathname, "/")
+}
+
+// canHaveCredentialsOrPort checks if the URL has a non-empty host and a non-file scheme,
+// as the WHATWG URL standard requires for usernames, passwords and ports
+func (u *Url) canHaveCredentialsOrPort() bool {
+	if !u.parser.callAdaBoolFunction("ada_has_hostname", u.cpointer) ||
+		u.parser.callAdaBoolFunction("ada_has_empty_hostname", u.cpointer) {
+		return false
+	}
+	return SchemeType(u.parser.callAdaUint8Function("ada_get_scheme_type", u.cpointer)) != SchemeFile
+}
+
+// hasHierarchicalPath checks if the URL has no opaque path, so it can take a host and a pathname
+func (u *Url) hasHierarchicalPath() bool {
+	return !u.hasOpaquePath()
+}
+
+// SetHrefErr sets the full URL, returning a *SetterError if it was not applied
+func (u *Url) SetHrefErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.setChecked("set href", "ada_set_href", s, nil)
+}
+
+// SetHostErr sets the host, returning a *SetterError if it was not applied.
+// URLs with an opaque path, such as "mailto:" URLs, report ErrNotApplicable.
+func (u *Url) SetHostErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.setChecked("set host", "ada_set_host", s, u.hasHierarchicalPath)
+}
+
+// SetHostnameErr sets the hostname, returning a *SetterError if it was not applied
+func (u *Url) SetHostnameErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.setChecked("set hostname", "ada_set_hostname", s, u.hasHierarchicalPath)
+}
+
+// SetProtocolErr sets the protocol, returning a *SetterError if it was not applied.
+// Switching between special and non-special schemes is rejected as ErrValueRejected.
+func (u *Url) SetProtocolErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	if err := u.setChecked("set protocol", "ada_set_protocol", s, nil); err != nil {
+		return err
+	}
+
+	// The standard ignores disallowed scheme changes instead of failing, so check the outcome.
+	// The parser drops ASCII tabs and newlines from the value before anything else.
+	scheme, _, _ := strings.Cut(removeTabAndNewline(s), ":")
+	fn := u.parser.getFunction("ada_get_protocol")
+	if fn == nil {
+		return nil
+	}
+	protocol, err := u.parser.readAdaString(fn, u.cpointer)
+	if err != nil {
+		return newSetterError("set protocol", s, ReasonEngineFailure, err)
+	}
+	if protocol != strings.ToLower(scheme)+":" {
+		return newSetterError("set protocol", s, ReasonValueRejected, ErrValueRejected)
+	}
+	return nil
+}
+
+// SetUsernameErr sets the username, returning a *SetterError if it was not applied.
+// URLs without a host or with the file scheme report ErrNotApplicable.
+func (u *Url) SetUsernameErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.setChecked("set username", "ada_set_username", s, u.canHaveCredentialsOrPort)
+}
+
+// SetPasswordErr sets the password, returning a *SetterError if it was not applied.
+// URLs without a host or with the file scheme report ErrNotApplicable.
+func (u *Url) SetPasswordErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.setChecked("set password", "ada_set_password", s, u.canHaveCredentialsOrPort)
+}
+
+// SetPortErr sets the port, returning a *SetterError if it was not applied.
+// URLs without a host or with the file scheme report ErrNotApplicable.
+func (u *Url) SetPortErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.setChecked("set port", "ada_set_port", s, u.canHaveCredentialsOrPort)
+}
+
+// SetPathnameErr sets the pathname, returning a *SetterError if it was not applied.
+// URLs with an opaque path report ErrNotApplicable.
+func (u *Url) SetPathnameErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.setChecked("set pathname", "ada_set_pathname", s, u.hasHierarchicalPath)
+}
+
+// SetSearchErr sets the search/query string, returning a *SetterError if the engine failed
+func (u *Url) SetSearchErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.setVoidChecked("set search", "ada_set_search", s)
+}
+
+// SetHashErr sets the hash/fragment, returning a *SetterError if the engine failed
+func (u *Url) SetHashErr(s string) error {
+	u.lock()
+	defer u.unlock()
+	return u.setVoidChecked("set hash", "ada_set_hash", s)
+}
+
+// removeTabAndNewline removes the ASCII tab and newline characters the URL parser ignores
+func removeTabAndNewline(s string) string {
+	return strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(s)
+}
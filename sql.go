@@ -0,0 +1,68 @@
+package goadawasm
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// Scan parses and normalizes a string or []byte column into the record, so that rows holding
+// invalid URLs fail at scan time with a *ParseError naming the value. NULL is rejected; scan
+// nullable columns into a NullURLRecord.
+func (r *URLRecord) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+		return errors.New("cannot scan NULL into URLRecord, use NullURLRecord")
+	default:
+		return fmt.Errorf("cannot scan %T into URLRecord", src)
+	}
+
+	record, err := ParseValue(s)
+	if err != nil {
+		return err
+	}
+	*r = record
+	return nil
+}
+
+// Value returns the normalized URL string for storage. The zero record is refused rather than
+// stored as an empty string that could not be scanned back; use NullURLRecord for optional URLs.
+func (r URLRecord) Value() (driver.Value, error) {
+	if r.IsZero() {
+		return nil, errZeroRecord
+	}
+	return r.components.Href, nil
+}
+
+// NullURLRecord is a URLRecord that may be NULL, like sql.NullString
+type NullURLRecord struct {
+	Record URLRecord
+	Valid  bool // Valid is true if Record is not NULL
+}
+
+// Scan parses a nullable column into the record, see URLRecord.Scan
+func (n *NullURLRecord) Scan(src any) error {
+	if src == nil {
+		n.Record, n.Valid = URLRecord{}, false
+		return nil
+	}
+	if err := n.Record.Scan(src); err != nil {
+		n.Valid = false
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// Value returns the normalized URL string, or nil for NULL
+func (n NullURLRecord) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+	return n.Record.Value()
+}
@@ -0,0 +1,283 @@
+package goadawasm
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// streamChunkSize is the number of lines a worker parses as one batch
+const streamChunkSize = 256
+
+// NormalizeOptions configures NormalizeStream
+type NormalizeOptions struct {
+	// Workers is the number of lines parsed in parallel, defaulting to the number of instances of the engine
+	Workers int
+	// Base, if not empty, is the URL every line is resolved against
+	Base string
+	// OnInvalid is called in input order for every line that is not a valid URL, with its 1-based number,
+	// and for every line the engine failed on even alone in a fresh instance, with an error matching ErrEngineFailure.
+	// These lines are left out of the output; they are dropped silently if OnInvalid is nil.
+	OnInvalid func(line int, input string, err error)
+}
+
+// streamChunk is a run of consecutive input lines, normalized by a worker and written in order
+type streamChunk struct {
+	lines   []string // Non-empty lines, without their line ending
+	numbers []int    // Number of each line
+	results []string // Href of each line, empty if invalid
+	errs    []error  // Error of each invalid line
+	done    chan struct{}
+}
+
+// NormalizeStream reads newline-delimited URLs from r and writes their WHATWG serialization to w,
+// one per line and in input order. Lines are parsed in parallel over the WASM instances of the engine.
+// Blank lines are skipped; surrounding spaces and a trailing "\r" are ignored.
+// It stops at the end of r, on the first read or write error, or when ctx is done.
+// When it stops early, it returns without waiting for a read in progress, which is left to finish
+// in the background; r is not closed, so callers that own it may close it to end that read.
+func NormalizeStream(ctx context.Context, r io.Reader, w io.Writer, opts *NormalizeOptions) error {
+	return defaultEngine().NormalizeStream(ctx, r, w, opts)
+}
+
+// NormalizeStream normalizes newline-delimited URLs from r to w using the parsers of the engine
+func (e *Engine) NormalizeStream(ctx context.Context, r io.Reader, w io.Writer, opts *NormalizeOptions) error {
+	if opts == nil {
+		opts = &NormalizeOptions{}
+	}
+	workers := opts.Workers
+	if workers < 1 {
+		workers = max(len(e.shards), 1)
+	}
+	if opts.Base != "" && !e.CanParse(opts.Base) {
+		return newParseError("parse", opts.Base, "", ReasonInvalidBase, ErrInvalidUrl)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Chunks go to the workers and, in the same order, to the writer
+	work := make(chan *streamChunk)
+	ordered := make(chan *streamChunk, 2*workers)
+
+	// The read error is sent before ordered is closed, so it is ready once the writer is through
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(work)
+		defer close(ordered)
+		readErr <- readChunks(ctx, r, work, ordered)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case chunk, ok := <-work:
+					if !ok {
+						return
+					}
+					e.normalizeChunk(chunk, opts.Base)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	defer wg.Wait()
+
+	err := writeChunks(ctx, w, ordered, opts.OnInvalid)
+	if err != nil {
+		// A read in progress may block for good, so the reader is left to finish on its own
+		cancel()
+		return err
+	}
+	return <-readErr
+}
+
+// readChunks splits r into chunks of lines and hands each of them to both channels
+func readChunks(ctx context.Context, r io.Reader, work, ordered chan<- *streamChunk) error {
+	reader := bufio.NewReader(r)
+	number := 0
+	chunk := &streamChunk{done: make(chan struct{})}
+
+	send := func() bool {
+		select {
+		case ordered <- chunk:
+		case <-ctx.Done():
+			return false
+		}
+		select {
+		case work <- chunk:
+		case <-ctx.Done():
+			return false
+		}
+		chunk = &streamChunk{done: make(chan struct{})}
+		return true
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			number++
+			if line = strings.TrimSpace(line); line != "" {
+				chunk.lines = append(chunk.lines, line)
+				chunk.numbers = append(chunk.numbers, number)
+			}
+			if len(chunk.lines) == streamChunkSize && !send() {
+				return ctx.Err()
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(chunk.lines) > 0 && !send() {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// normalizeChunk parses the lines of a chunk as one batch and marks the chunk done.
+// Lines whose URL died with an instance another line made fail are parsed again in the next batch,
+// while a line that made an instance fail itself is parsed once more alone in a fresh instance.
+func (e *Engine) normalizeChunk(chunk *streamChunk, base string) {
+	defer close(chunk.done)
+
+	chunk.results = make([]string, len(chunk.lines))
+	chunk.errs = make([]error, len(chunk.lines))
+	pending := make([]int, len(chunk.lines))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for len(pending) > 0 {
+		lines := make([]string, len(pending))
+		for j, i := range pending {
+			lines[j] = chunk.lines[i]
+		}
+		var results []BatchResult
+		if base != "" {
+			results = e.ParseBatchWithBase(lines, base)
+		} else {
+			results = e.ParseBatch(lines)
+		}
+
+		died := pending[:0]
+		for j, result := range results {
+			i := pending[j]
+			if result.Url == nil {
+				if errors.Is(result.Err, ErrEngineFailure) {
+					chunk.results[i], chunk.errs[i] = e.normalizeAlone(chunk.lines[i], base)
+				} else {
+					chunk.errs[i] = result.Err
+				}
+				continue
+			}
+			// Href would read an empty string from a URL whose instance was discarded meanwhile
+			c, err := result.Url.Components()
+			result.Url.Free()
+			if err != nil {
+				died = append(died, i)
+				continue
+			}
+			chunk.results[i] = c.Href
+		}
+		pending = died
+	}
+}
+
+// normalizeAlone parses a line the engine failed on in a fresh instance of its own,
+// so that a failure there comes from the line itself
+func (e *Engine) normalizeAlone(line, base string) (string, error) {
+	op, parse := "parse", "ada_parse"
+	strs := []string{line}
+	if base != "" {
+		op, parse = "parse with base", "ada_parse_with_base"
+		strs = append(strs, base)
+	}
+	fail := func(err error) (string, error) {
+		return "", newParseError(op, line, base, ReasonEngineFailure, err)
+	}
+
+	parser, err := e.newParser()
+	if err != nil {
+		return fail(err)
+	}
+	defer parser.Close()
+
+	parseFunc := parser.getFunction(parse)
+	hrefFunc := parser.getFunction("ada_get_href")
+	if parseFunc == nil || hrefFunc == nil {
+		return fail(engineFailure(parse + " function not found"))
+	}
+	args, release, err := parser.writeStringArgs(strs...)
+	if err != nil {
+		return fail(err)
+	}
+	defer release()
+
+	results, err := parser.call(parseFunc, args...)
+	if err != nil {
+		return fail(err)
+	}
+	urlObjPtr := uint32(results[0])
+	if urlObjPtr == 0 {
+		return fail(engineFailure("empty url object!"))
+	}
+	if !parser.callAdaBoolFunction("ada_is_valid", urlObjPtr) {
+		return "", newParseError(op, line, base, ReasonInvalidInput, ErrInvalidUrl)
+	}
+	href, err := parser.readAdaString(hrefFunc, urlObjPtr)
+	if err != nil {
+		return fail(err)
+	}
+	return href, nil
+}
+
+// writeChunks writes the hrefs of the chunks in order as they are done, reporting invalid lines
+func writeChunks(ctx context.Context, w io.Writer, ordered <-chan *streamChunk, onInvalid func(int, string, error)) error {
+	out := bufio.NewWriter(w)
+	for {
+		var chunk *streamChunk
+		select {
+		case chunk = <-ordered:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if chunk == nil {
+			break
+		}
+		select {
+		case <-chunk.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		for i, href := range chunk.results {
+			if err := chunk.errs[i]; err != nil {
+				if onInvalid != nil {
+					onInvalid(chunk.numbers[i], chunk.lines[i], err)
+				}
+				continue
+			}
+			out.WriteString(href)
+			if err := out.WriteByte('\n'); err != nil {
+				return err
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return out.Flush()
+}
@@ -0,0 +1,517 @@
+package goadawasm
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// SyncUrl is a concurrency-safe Url: every call on the wrapped URL is serialized,
+// including Free and the multi-call sequences run by Do.
+type SyncUrl struct {
+	url   *Url
+	mutex sync.Mutex
+}
+
+// NewSync parses the given string into a concurrency-safe URL
+func NewSync(urlstring string) (*SyncUrl, error) {
+	url, err := New(urlstring)
+	if err != nil {
+		return nil, err
+	}
+	return url.Sync(), nil
+}
+
+// NewSyncWithBase parses the given strings into a concurrency-safe URL with a base URL
+func NewSyncWithBase(urlstring, basestring string) (*SyncUrl, error) {
+	url, err := NewWithBase(urlstring, basestring)
+	if err != nil {
+		return nil, err
+	}
+	return url.Sync(), nil
+}
+
+// Sync wraps the URL into a SyncUrl, which takes over ownership of it.
+// The URL must no longer be used directly afterwards.
+func (u *Url) Sync() *SyncUrl {
+	return &SyncUrl{url: u}
+}
+
+// Do runs fn with exclusive access to the wrapped URL, so several calls can be made atomically.
+// fn must not keep u or call methods of the SyncUrl.
+func (su *SyncUrl) Do(fn func(u *Url)) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	fn(su.url)
+}
+
+// Free manually frees the URL object
+func (su *SyncUrl) Free() {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	su.url.Free()
+}
+
+// Clone returns an independent copy of the URL backed by ada_copy.
+// The copy is locked independently of the original.
+func (su *SyncUrl) Clone() (*SyncUrl, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	clone, err := su.url.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return clone.Sync(), nil
+}
+
+// Valid checks if the URL is valid
+func (su *SyncUrl) Valid() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Valid()
+}
+
+// HasCredentials checks if the URL has credentials
+func (su *SyncUrl) HasCredentials() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HasCredentials()
+}
+
+// HasEmptyHostname checks if the URL has an empty hostname
+func (su *SyncUrl) HasEmptyHostname() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HasEmptyHostname()
+}
+
+// HasHostname checks if the URL has a hostname
+func (su *SyncUrl) HasHostname() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HasHostname()
+}
+
+// HasNonEmptyUsername checks if the URL has a non-empty username
+func (su *SyncUrl) HasNonEmptyUsername() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HasNonEmptyUsername()
+}
+
+// HasNonEmptyPassword checks if the URL has a non-empty password
+func (su *SyncUrl) HasNonEmptyPassword() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HasNonEmptyPassword()
+}
+
+// HasPort checks if the URL has a port
+func (su *SyncUrl) HasPort() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HasPort()
+}
+
+// HasPassword checks if the URL has a password
+func (su *SyncUrl) HasPassword() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HasPassword()
+}
+
+// HasHash checks if the URL has a hash
+func (su *SyncUrl) HasHash() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HasHash()
+}
+
+// HasSearch checks if the URL has a search/query string
+func (su *SyncUrl) HasSearch() bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HasSearch()
+}
+
+// Href returns the full URL string
+func (su *SyncUrl) Href() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Href()
+}
+
+// Username returns the username
+func (su *SyncUrl) Username() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Username()
+}
+
+// Password returns the password
+func (su *SyncUrl) Password() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Password()
+}
+
+// Port returns the port
+func (su *SyncUrl) Port() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Port()
+}
+
+// Hash returns the hash/fragment
+func (su *SyncUrl) Hash() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Hash()
+}
+
+// Host returns the host (hostname + port)
+func (su *SyncUrl) Host() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Host()
+}
+
+// Hostname returns the hostname
+func (su *SyncUrl) Hostname() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Hostname()
+}
+
+// Pathname returns the pathname
+func (su *SyncUrl) Pathname() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Pathname()
+}
+
+// Search returns the search/query string
+func (su *SyncUrl) Search() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Search()
+}
+
+// Protocol returns the protocol/scheme
+func (su *SyncUrl) Protocol() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Protocol()
+}
+
+// Origin returns the serialized origin ("null" for opaque origins)
+func (su *SyncUrl) Origin() string {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Origin()
+}
+
+// SetHref sets the full URL
+func (su *SyncUrl) SetHref(s string) bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHref(s)
+}
+
+// SetHost sets the host
+func (su *SyncUrl) SetHost(s string) bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHost(s)
+}
+
+// SetHostname sets the hostname
+func (su *SyncUrl) SetHostname(s string) bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHostname(s)
+}
+
+// SetProtocol sets the protocol
+func (su *SyncUrl) SetProtocol(s string) bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetProtocol(s)
+}
+
+// SetUsername sets the username
+func (su *SyncUrl) SetUsername(s string) bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetUsername(s)
+}
+
+// SetPassword sets the password
+func (su *SyncUrl) SetPassword(s string) bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetPassword(s)
+}
+
+// SetPort sets the port
+func (su *SyncUrl) SetPort(s string) bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetPort(s)
+}
+
+// SetPathname sets the pathname
+func (su *SyncUrl) SetPathname(s string) bool {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetPathname(s)
+}
+
+// SetSearch sets the search/query string
+func (su *SyncUrl) SetSearch(s string) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	su.url.SetSearch(s)
+}
+
+// SetHash sets the hash/fragment
+func (su *SyncUrl) SetHash(s string) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	su.url.SetHash(s)
+}
+
+// ClearHash removes the hash/fragment, including the "#" delimiter
+func (su *SyncUrl) ClearHash() {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	su.url.ClearHash()
+}
+
+// ClearPort removes the explicit port
+func (su *SyncUrl) ClearPort() {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	su.url.ClearPort()
+}
+
+// ClearSearch removes the search/query string, including the "?" delimiter
+func (su *SyncUrl) ClearSearch() {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	su.url.ClearSearch()
+}
+
+// Components returns all components of the URL with a single href read,
+// which is considerably cheaper than calling every getter in turn
+func (su *SyncUrl) Components() (Components, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Components()
+}
+
+// HostType returns the kind of host of the URL
+func (su *SyncUrl) HostType() HostType {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.HostType()
+}
+
+// SchemeType returns the kind of scheme of the URL
+func (su *SyncUrl) SchemeType() SchemeType {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SchemeType()
+}
+
+// Err returns an error matching ErrEngineFailure once the URL is no longer usable
+func (su *SyncUrl) Err() error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Err()
+}
+
+// Diagram returns ada's annotated rendering of the URL's internal component offsets. See Url.Diagram.
+func (su *SyncUrl) Diagram() (string, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Diagram()
+}
+
+// Detach returns a URLRecord snapshot of the URL's current state.
+// The URL itself stays valid and must still be freed.
+func (su *SyncUrl) Detach() (URLRecord, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.Detach()
+}
+
+// ToNetURL converts the URL to a net/url.URL whose String() is exactly Href. See Url.ToNetURL.
+func (su *SyncUrl) ToNetURL() (*url.URL, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.ToNetURL()
+}
+
+// SearchParams parses the search/query string of the URL into SearchParams.
+// The result is independent of the URL; use SetSearchParams to write changes back.
+func (su *SyncUrl) SearchParams() (*SearchParams, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SearchParams()
+}
+
+// SetSearchParams replaces the search/query string of the URL with the serialized params
+func (su *SyncUrl) SetSearchParams(params *SearchParams) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	su.url.SetSearchParams(params)
+}
+
+// SetHrefContext sets the full URL, stopping early when ctx is done.
+// Like the other context-taking setters, it can only stop a running call on URLs
+// created by ParseContext or ParseWithBaseContext; for other URLs ctx is checked up front.
+func (su *SyncUrl) SetHrefContext(ctx context.Context, s string) (bool, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHrefContext(ctx, s)
+}
+
+// SetHostContext sets the host, stopping early when ctx is done
+func (su *SyncUrl) SetHostContext(ctx context.Context, s string) (bool, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHostContext(ctx, s)
+}
+
+// SetHostnameContext sets the hostname, stopping early when ctx is done
+func (su *SyncUrl) SetHostnameContext(ctx context.Context, s string) (bool, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHostnameContext(ctx, s)
+}
+
+// SetProtocolContext sets the protocol, stopping early when ctx is done
+func (su *SyncUrl) SetProtocolContext(ctx context.Context, s string) (bool, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetProtocolContext(ctx, s)
+}
+
+// SetUsernameContext sets the username, stopping early when ctx is done
+func (su *SyncUrl) SetUsernameContext(ctx context.Context, s string) (bool, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetUsernameContext(ctx, s)
+}
+
+// SetPasswordContext sets the password, stopping early when ctx is done
+func (su *SyncUrl) SetPasswordContext(ctx context.Context, s string) (bool, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetPasswordContext(ctx, s)
+}
+
+// SetPortContext sets the port, stopping early when ctx is done
+func (su *SyncUrl) SetPortContext(ctx context.Context, s string) (bool, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetPortContext(ctx, s)
+}
+
+// SetPathnameContext sets the pathname, stopping early when ctx is done
+func (su *SyncUrl) SetPathnameContext(ctx context.Context, s string) (bool, error) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetPathnameContext(ctx, s)
+}
+
+// SetSearchContext sets the search/query string, stopping early when ctx is done
+func (su *SyncUrl) SetSearchContext(ctx context.Context, s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetSearchContext(ctx, s)
+}
+
+// SetHashContext sets the hash/fragment, stopping early when ctx is done
+func (su *SyncUrl) SetHashContext(ctx context.Context, s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHashContext(ctx, s)
+}
+
+// SetHrefErr sets the full URL, returning a *SetterError if it was not applied
+func (su *SyncUrl) SetHrefErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHrefErr(s)
+}
+
+// SetHostErr sets the host, returning a *SetterError if it was not applied.
+// URLs with an opaque path, such as "mailto:" URLs, report ErrNotApplicable.
+func (su *SyncUrl) SetHostErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHostErr(s)
+}
+
+// SetHostnameErr sets the hostname, returning a *SetterError if it was not applied
+func (su *SyncUrl) SetHostnameErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHostnameErr(s)
+}
+
+// SetProtocolErr sets the protocol, returning a *SetterError if it was not applied.
+// Switching between special and non-special schemes is rejected as ErrValueRejected.
+func (su *SyncUrl) SetProtocolErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetProtocolErr(s)
+}
+
+// SetUsernameErr sets the username, returning a *SetterError if it was not applied.
+// URLs without a host or with the file scheme report ErrNotApplicable.
+func (su *SyncUrl) SetUsernameErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetUsernameErr(s)
+}
+
+// SetPasswordErr sets the password, returning a *SetterError if it was not applied.
+// URLs without a host or with the file scheme report ErrNotApplicable.
+func (su *SyncUrl) SetPasswordErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetPasswordErr(s)
+}
+
+// SetPortErr sets the port, returning a *SetterError if it was not applied.
+// URLs without a host or with the file scheme report ErrNotApplicable.
+func (su *SyncUrl) SetPortErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetPortErr(s)
+}
+
+// SetPathnameErr sets the pathname, returning a *SetterError if it was not applied.
+// URLs with an opaque path report ErrNotApplicable.
+func (su *SyncUrl) SetPathnameErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetPathnameErr(s)
+}
+
+// SetSearchErr sets the search/query string, returning a *SetterError if the engine failed
+func (su *SyncUrl) SetSearchErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetSearchErr(s)
+}
+
+// SetHashErr sets the hash/fragment, returning a *SetterError if the engine failed
+func (su *SyncUrl) SetHashErr(s string) error {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+	return su.url.SetHashErr(s)
+}
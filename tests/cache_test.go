@@ -0,0 +1,144 @@
+package goadawasm_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+// cacheFiles returns the contents of all regular files below dir
+func cacheFiles(t *testing.T, dir string) map[string][]byte {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[path] = content
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk cache dir: %v", err)
+	}
+	return files
+}
+
+// checkEngine verifies that an engine parses URLs and closes cleanly
+func checkEngine(t *testing.T, engine *goadawasm.Engine) {
+	url, err := engine.New("https://www.GOoglé.com/./path/../path2/")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	compareString(t, "https://www.xn--googl-fsa.com/path2/", url.Href(), "Expected normalized url")
+	url.Free()
+
+	if err := engine.Close(); err != nil {
+		t.Errorf("failed to close engine: %v", err)
+	}
+}
+
+func TestCompilationCacheDir(t *testing.T) {
+	dir := t.TempDir()
+
+	engine, err := goadawasm.NewEngine(goadawasm.WithCompilationCacheDir(dir))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	checkEngine(t, engine)
+
+	files := cacheFiles(t, dir)
+	if len(files) == 0 {
+		t.Fatal("expected compiled module in cache dir")
+	}
+
+	// A second engine loads the cached module instead of writing a new one
+	engine, err = goadawasm.NewEngine(goadawasm.WithCompilationCacheDir(dir))
+	if err != nil {
+		t.Fatalf("failed to create engine from cache: %v", err)
+	}
+	checkEngine(t, engine)
+
+	if len(cacheFiles(t, dir)) != len(files) {
+		t.Error("expected cache to be reused")
+	}
+}
+
+func TestCompilationCacheDirEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(goadawasm.CacheDirEnv, dir)
+
+	engine, err := goadawasm.NewEngine()
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	checkEngine(t, engine)
+
+	if len(cacheFiles(t, dir)) == 0 {
+		t.Error("expected compiled module in cache dir from environment")
+	}
+
+	// The option takes precedence over the environment
+	optionDir := t.TempDir()
+	engine, err = goadawasm.NewEngine(goadawasm.WithCompilationCacheDir(optionDir))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	checkEngine(t, engine)
+
+	if len(cacheFiles(t, optionDir)) == 0 {
+		t.Error("expected compiled module in cache dir from option")
+	}
+}
+
+func TestCompilationCacheCorrupted(t *testing.T) {
+	dir := t.TempDir()
+
+	engine, err := goadawasm.NewEngine(goadawasm.WithCompilationCacheDir(dir))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	checkEngine(t, engine)
+
+	garbage := []byte("not a compiled module")
+	for path := range cacheFiles(t, dir) {
+		if err := os.WriteFile(path, garbage, 0o600); err != nil {
+			t.Fatalf("failed to corrupt cache: %v", err)
+		}
+	}
+
+	engine, err = goadawasm.NewEngine(goadawasm.WithCompilationCacheDir(dir))
+	if err != nil {
+		t.Fatalf("failed to create engine from corrupted cache: %v", err)
+	}
+	checkEngine(t, engine)
+
+	files := cacheFiles(t, dir)
+	if len(files) == 0 {
+		t.Fatal("expected cache to be rebuilt")
+	}
+	for path, content := range files {
+		if string(content) == string(garbage) {
+			t.Errorf("corrupted cache entry %s was not replaced", path)
+		}
+	}
+}
+
+func TestCompilationCacheUnusable(t *testing.T) {
+	// A regular file where the cache directory should be
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+
+	engine, err := goadawasm.NewEngine(goadawasm.WithCompilationCacheDir(file))
+	if err != nil {
+		t.Fatalf("expected fallback to uncached engine, got %v", err)
+	}
+	checkEngine(t, engine)
+}
@@ -0,0 +1,139 @@
+package goadawasm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestParseContext(t *testing.T) {
+	url, err := goadawasm.ParseContext(context.Background(), "https://www.GOogle.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer url.Free()
+	compareString(t, "https://www.google.com/", url.Href(), "Expected normalized url")
+
+	relative, err := goadawasm.ParseWithBaseContext(context.Background(), "../other", "https://example.com/base/path")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer relative.Free()
+	compareString(t, "https://example.com/other", relative.Href(), "Expected resolved url")
+
+	if _, err := goadawasm.ParseContext(context.Background(), "not-a-url"); !errors.Is(err, goadawasm.ErrInvalidUrl) {
+		t.Errorf("expected ErrInvalidUrl, got %v", err)
+	}
+}
+
+func TestParseContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := goadawasm.ParseContext(ctx, "https://example.com/")
+	var interrupted *goadawasm.InterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("expected InterruptedError, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	_, err = goadawasm.ParseWithBaseContext(ctx, "path", "https://example.com/")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestParseContextDeadline(t *testing.T) {
+	// Large enough that parsing cannot finish before the deadline
+	input := "https://example.com/" + strings.Repeat("a/../é%41", 4<<20)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	url, err := goadawasm.ParseContext(ctx, input)
+	if err == nil {
+		url.Free()
+		t.Skip("parsing finished before the deadline")
+	}
+	var interrupted *goadawasm.InterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("expected InterruptedError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// The interrupted instance must not be handed out again
+	for i := 0; i < 20; i++ {
+		url, err := goadawasm.New("https://example.com/after")
+		if err != nil {
+			t.Fatalf("parse after interruption failed: %v", err)
+		}
+		compareString(t, "https://example.com/after", url.Href(), "Expected working parser after interruption")
+		url.Free()
+	}
+}
+
+func TestSetterContext(t *testing.T) {
+	url, err := goadawasm.New("https://example.com/path")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	ctx := context.Background()
+	if ok, err := url.SetPortContext(ctx, "8080"); !ok || err != nil {
+		t.Errorf("SetPortContext: expected success, got %v, %v", ok, err)
+	}
+	if ok, err := url.SetPortContext(ctx, "invalid-port"); ok || err != nil {
+		t.Errorf("SetPortContext: expected rejection without error, got %v, %v", ok, err)
+	}
+	if err := url.SetSearchContext(ctx, "?q=1"); err != nil {
+		t.Errorf("SetSearchContext: unexpected error: %v", err)
+	}
+	if err := url.SetHashContext(ctx, "top"); err != nil {
+		t.Errorf("SetHashContext: unexpected error: %v", err)
+	}
+	compareString(t, "https://example.com:8080/path?q=1#top", url.Href(), "Expected updated url")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := url.SetHrefContext(canceled, "https://other.com/"); !errors.Is(err, context.Canceled) {
+		t.Errorf("SetHrefContext: expected context.Canceled, got %v", err)
+	}
+	// A context that is already done never reaches the instance
+	compareString(t, "https://example.com:8080/path?q=1#top", url.Href(), "Expected unchanged url")
+}
+
+func TestSetterContextInterrupted(t *testing.T) {
+	url, err := goadawasm.ParseContext(context.Background(), "https://example.com/path")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	// Large enough that the setter cannot finish before the deadline
+	input := "https://example.com/" + strings.Repeat("a/../é%41", 4<<20)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	ok, err := url.SetHrefContext(ctx, input)
+	if err == nil {
+		t.Skipf("setter finished before the deadline (ok = %v)", ok)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// The URL's instance is gone; further use must fail cleanly rather than crash
+	if _, err := url.SetHrefContext(context.Background(), "https://other.com/"); err == nil {
+		t.Error("expected error from URL with discarded instance")
+	}
+}
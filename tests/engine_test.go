@@ -0,0 +1,113 @@
+package goadawasm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []goadawasm.Option
+	}{
+		{"default", nil},
+		{"interpreter", []goadawasm.Option{goadawasm.WithInterpreter()}},
+		{"runtime config", []goadawasm.Option{goadawasm.WithRuntimeConfig(wazero.NewRuntimeConfig().WithMemoryLimitPages(512))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := goadawasm.NewEngine(tt.options...)
+			if err != nil {
+				t.Fatalf("failed to create engine: %v", err)
+			}
+			defer engine.Close()
+
+			url, err := engine.NewWithBase("../other?q=1", "https://www.GOogle.com/dir/page")
+			if err != nil {
+				t.Fatalf("failed to parse URL: %v", err)
+			}
+			defer url.Free()
+			compareString(t, "https://www.google.com/other?q=1", url.Href(), "Expected resolved url")
+
+			if _, err := engine.New("not-a-url"); !errors.Is(err, goadawasm.ErrInvalidUrl) {
+				t.Errorf("expected ErrInvalidUrl, got %v", err)
+			}
+			if !engine.CanParse("https://example.com/") {
+				t.Error("CanParse should be true")
+			}
+
+			params, err := url.SearchParams()
+			if err != nil {
+				t.Fatalf("failed to get search params: %v", err)
+			}
+			defer params.Free()
+			if value, _ := params.Get("q"); value != "1" {
+				t.Errorf("expected q=1, got %q", value)
+			}
+
+			ascii, err := engine.ToASCII("bücher.de")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			compareString(t, "xn--bcher-kva.de", ascii, "Expected ASCII domain")
+
+			interruptible, err := engine.ParseContext(context.Background(), "https://example.com/ctx")
+			if err != nil {
+				t.Fatalf("failed to parse URL with context: %v", err)
+			}
+			defer interruptible.Free()
+			compareString(t, "/ctx", interruptible.Pathname(), "Expected pathname")
+		})
+	}
+}
+
+func TestEngineClose(t *testing.T) {
+	engine, err := goadawasm.NewEngine()
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+
+	url, err := engine.New("https://example.com/path")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	interruptible, err := engine.ParseContext(context.Background(), "https://example.com/path")
+	if err != nil {
+		t.Fatalf("failed to parse URL with context: %v", err)
+	}
+
+	if err := engine.Close(); err != nil {
+		t.Fatalf("failed to close engine: %v", err)
+	}
+	if err := engine.Close(); err != nil {
+		t.Errorf("closing twice should be a no-op, got %v", err)
+	}
+
+	if _, err := engine.New("https://example.com/"); !errors.Is(err, goadawasm.ErrEngineClosed) {
+		t.Errorf("expected ErrEngineClosed, got %v", err)
+	}
+	if _, err := engine.ParseContext(context.Background(), "https://example.com/"); !errors.Is(err, goadawasm.ErrEngineClosed) {
+		t.Errorf("expected ErrEngineClosed, got %v", err)
+	}
+	if engine.CanParse("https://example.com/") {
+		t.Error("CanParse should be false on a closed engine")
+	}
+
+	// URLs of a closed engine are unusable but must not crash
+	compareString(t, "", url.Href(), "Expected empty href from closed engine")
+	url.Free()
+	interruptible.Free()
+
+	// Other engines are unaffected
+	other, err := goadawasm.New("https://example.com/other")
+	if err != nil {
+		t.Fatalf("default engine affected by closing another engine: %v", err)
+	}
+	defer other.Free()
+	compareString(t, "https://example.com/other", other.Href(), "Expected default engine to keep working")
+}
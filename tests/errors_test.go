@@ -0,0 +1,114 @@
+package goadawasm_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		base     string
+		withBase bool
+		reason   string
+		target   error
+		message  string
+	}{
+		{"empty input", "", "", false, goadawasm.ReasonEmptyInput, goadawasm.ErrEmptyString, `parse "": empty input`},
+		{"invalid input", "not a url", "", false, goadawasm.ReasonInvalidInput, goadawasm.ErrInvalidUrl, `parse "not a url": invalid input`},
+		{"empty input and base", "", "", true, goadawasm.ReasonEmptyInput, goadawasm.ErrEmptyString, `parse with base "" against "": empty input`},
+		{"invalid base", "/path", "not a base", true, goadawasm.ReasonInvalidBase, goadawasm.ErrInvalidUrl, `parse with base "/path" against "not a base": invalid base`},
+		{"invalid input with base", "https://exa mple.com", "https://example.com/", true, goadawasm.ReasonInvalidInput, goadawasm.ErrInvalidUrl, `parse with base "https://exa mple.com" against "https://example.com/": invalid input`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.withBase {
+				_, err = goadawasm.NewWithBase(tt.input, tt.base)
+			} else {
+				_, err = goadawasm.New(tt.input)
+			}
+
+			var parseErr *goadawasm.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a ParseError, got %v", err)
+			}
+			if !errors.Is(err, tt.target) {
+				t.Errorf("expected error to match %v, got %v", tt.target, err)
+			}
+			compareString(t, tt.reason, parseErr.Reason, "Expected reason")
+			compareString(t, tt.input, parseErr.Input, "Expected input")
+			compareString(t, tt.base, parseErr.Base, "Expected base")
+			compareString(t, tt.message, err.Error(), "Expected message")
+		})
+	}
+}
+
+func TestParseErrorTruncatesInput(t *testing.T) {
+	// A multi-byte rune straddles the truncation point
+	input := "https://exa mple.com/" + strings.Repeat("é", 100000)
+	_, err := goadawasm.New(input)
+
+	var parseErr *goadawasm.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a ParseError, got %v", err)
+	}
+	if len(parseErr.Input) > 300 {
+		t.Errorf("expected truncated input, got %d bytes", len(parseErr.Input))
+	}
+	if !utf8.ValidString(parseErr.Input) {
+		t.Errorf("truncated input is not valid UTF-8: %q", parseErr.Input)
+	}
+	if !strings.HasPrefix(input, strings.TrimSuffix(parseErr.Input, "... (200021 bytes)")) {
+		t.Errorf("expected a prefix of the input, got %q", parseErr.Input)
+	}
+	if !strings.HasSuffix(parseErr.Input, "... (200021 bytes)") {
+		t.Errorf("expected the original length to be noted, got %q", parseErr.Input)
+	}
+}
+
+func TestParseErrorEngineClosed(t *testing.T) {
+	engine, err := goadawasm.NewEngine()
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	engine.Close()
+
+	_, err = engine.New("https://example.com/")
+	var parseErr *goadawasm.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a ParseError, got %v", err)
+	}
+	compareString(t, goadawasm.ReasonEngineFailure, parseErr.Reason, "Expected reason")
+	if !errors.Is(err, goadawasm.ErrEngineClosed) {
+		t.Errorf("expected ErrEngineClosed, got %v", err)
+	}
+	if !errors.Is(err, goadawasm.ErrEngineFailure) {
+		t.Errorf("expected ErrEngineFailure, got %v", err)
+	}
+
+	_, err = engine.ParseContext(t.Context(), "https://example.com/")
+	if !errors.As(err, &parseErr) || parseErr.Reason != goadawasm.ReasonEngineFailure {
+		t.Errorf("expected a ParseError with an engine failure, got %v", err)
+	}
+	for name, err := range map[string]error{
+		"ParseContext":      err,
+		"ParseSearchParams": func() error { _, err := engine.ParseSearchParams("a=1"); return err }(),
+		"ToASCII":           func() error { _, err := engine.ToASCII("example.com"); return err }(),
+		"NewWithBase":       func() error { _, err := engine.NewWithBase("/a", "https://example.com/"); return err }(),
+		"ParseWithBaseContext": func() error {
+			_, err := engine.ParseWithBaseContext(t.Context(), "/a", "https://example.com/")
+			return err
+		}(),
+	} {
+		if !errors.Is(err, goadawasm.ErrEngineFailure) || !errors.Is(err, goadawasm.ErrEngineClosed) {
+			t.Errorf("%s: expected ErrEngineFailure and ErrEngineClosed, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package goadawasm_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tetratelabs/wazero"
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+// newFaultyEngine creates an engine whose instances trap when ada runs out of memory,
+// which a URL of some tens of kilobytes is enough for
+func newFaultyEngine(t *testing.T) *goadawasm.Engine {
+	t.Helper()
+	engine, err := goadawasm.NewEngine(
+		goadawasm.WithInstances(1),
+		goadawasm.WithRuntimeConfig(wazero.NewRuntimeConfig().WithMemoryLimitPages(8)),
+	)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	t.Cleanup(func() { engine.Close() })
+	return engine
+}
+
+// hugeURL returns a URL that makes ada trap on an engine from newFaultyEngine
+func hugeURL() string {
+	return "https://example.com/" + strings.Repeat("%", 60000)
+}
+
+func TestEngineFailureRecovery(t *testing.T) {
+	engine := newFaultyEngine(t)
+
+	live, err := engine.New("https://example.com/live")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer live.Free()
+
+	_, err = engine.New(hugeURL())
+	if !errors.Is(err, goadawasm.ErrEngineFailure) {
+		t.Fatalf("expected ErrEngineFailure, got %v", err)
+	}
+	var parseErr *goadawasm.ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected a ParseError, got %v", err)
+	}
+	compareString(t, goadawasm.ReasonEngineFailure, parseErr.Reason, "Expected reason")
+	if strings.Contains(err.Error(), "\n") {
+		t.Errorf("expected a single line message, got %q", err.Error())
+	}
+
+	// The failed instance is replaced by a fresh one
+	url, err := engine.New("https://example.com/after")
+	if err != nil {
+		t.Fatalf("failed to parse URL after a failure: %v", err)
+	}
+	defer url.Free()
+	compareString(t, "https://example.com/after", url.Href(), "Expected href")
+	if n := engine.Instances(); n != 1 {
+		t.Errorf("expected 1 open instance, got %d", n)
+	}
+
+	// URLs of the failed instance report the failure instead of using it
+	if _, err := live.Components(); !errors.Is(err, goadawasm.ErrEngineFailure) {
+		t.Errorf("expected ErrEngineFailure from a URL of the failed instance, got %v", err)
+	}
+	compareString(t, "", live.Href(), "Expected empty href from a URL of the failed instance")
+	if err := live.Err(); !errors.Is(err, goadawasm.ErrEngineFailure) {
+		t.Errorf("expected Err to report ErrEngineFailure, got %v", err)
+	}
+	if err := url.Err(); err != nil {
+		t.Errorf("expected no error for a URL of the fresh instance, got %v", err)
+	}
+
+	// Nor do they claim a scheme or host type
+	if scheme := live.SchemeType(); scheme == goadawasm.SchemeHTTP || scheme.IsSpecial() {
+		t.Errorf("expected an unknown scheme type from a URL of the failed instance, got %v", scheme)
+	}
+	compareString(t, "unknown", live.HostType().String(), "Expected unknown host type")
+}
+
+func TestEngineFailureSyncUrl(t *testing.T) {
+	engine := newFaultyEngine(t)
+
+	plain, err := engine.New("https://example.com/live")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	live := plain.Sync()
+	defer live.Free()
+	if err := live.Err(); err != nil {
+		t.Errorf("expected no error before the failure, got %v", err)
+	}
+
+	if _, err := engine.New(hugeURL()); !errors.Is(err, goadawasm.ErrEngineFailure) {
+		t.Fatalf("expected ErrEngineFailure, got %v", err)
+	}
+	if err := live.Err(); !errors.Is(err, goadawasm.ErrEngineFailure) {
+		t.Errorf("expected Err to report ErrEngineFailure, got %v", err)
+	}
+}
+
+func TestEngineFailureInSetter(t *testing.T) {
+	engine := newFaultyEngine(t)
+
+	url, err := engine.New("https://example.com/")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	err = url.SetHrefErr(hugeURL())
+	var setterErr *goadawasm.SetterError
+	if !errors.As(err, &setterErr) {
+		t.Fatalf("expected a SetterError, got %v", err)
+	}
+	compareString(t, goadawasm.ReasonEngineFailure, setterErr.Reason, "Expected reason")
+	if !errors.Is(err, goadawasm.ErrEngineFailure) {
+		t.Errorf("expected ErrEngineFailure, got %v", err)
+	}
+
+	if !engine.CanParse("https://example.com/") {
+		t.Error("CanParse should succeed on a fresh instance")
+	}
+}
+
+func TestEngineFailureInterruptible(t *testing.T) {
+	engine := newFaultyEngine(t)
+
+	_, err := engine.ParseContext(context.Background(), hugeURL())
+	if !errors.Is(err, goadawasm.ErrEngineFailure) {
+		t.Fatalf("expected ErrEngineFailure, got %v", err)
+	}
+	var interrupted *goadawasm.InterruptedError
+	if errors.As(err, &interrupted) {
+		t.Errorf("a trap must not be reported as an interruption: %v", err)
+	}
+
+	url, err := engine.ParseContext(context.Background(), "https://example.com/")
+	if err != nil {
+		t.Fatalf("failed to parse URL after a failure: %v", err)
+	}
+	url.Free()
+}
+
+func TestOutOfMemoryKeepsInstance(t *testing.T) {
+	engine := newFaultyEngine(t)
+
+	live, err := engine.New("https://example.com/live")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer live.Free()
+
+	// Too large to even be copied into WASM memory, so ada is never called
+	_, err = engine.New("https://example.com/" + strings.Repeat("a", 1<<20))
+	if !errors.Is(err, goadawasm.ErrEngineFailure) {
+		t.Fatalf("expected ErrEngineFailure, got %v", err)
+	}
+
+	compareString(t, "https://example.com/live", live.Href(), "Expected the instance to stay usable")
+}
@@ -0,0 +1,75 @@
+//go:build goadawasm_debug
+
+package goadawasm_test
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestDebugConcurrentUseDetected(t *testing.T) {
+	url, err := goadawasm.New("https://example.com/path")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	var detected atomic.Bool
+	var message atomic.Value
+	use := func(fn func()) {
+		defer func() {
+			if r := recover(); r != nil {
+				message.Store(r)
+				detected.Store(true)
+			}
+		}()
+		for i := 0; i < 100000 && !detected.Load(); i++ {
+			fn()
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		use(func() { url.SetHref("https://example.com/" + strings.Repeat("a/", 64)) })
+	}()
+	go func() {
+		defer wg.Done()
+		use(func() { url.Pathname() })
+	}()
+	wg.Wait()
+
+	if !detected.Load() {
+		t.Fatal("expected concurrent use to panic")
+	}
+	text, _ := message.Load().(string)
+	if !strings.Contains(text, "not concurrency-safe") {
+		t.Fatalf("unexpected panic %q", text)
+	}
+	if !strings.Contains(text, "Url.SetHref") && !strings.Contains(text, "Url.Pathname") {
+		t.Errorf("expected panic to name the method, got %q", text)
+	}
+}
+
+func TestDebugNestedUseAllowed(t *testing.T) {
+	url, err := goadawasm.New("https://example.com/path?q=1")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	// Detach calls other Url methods on the same goroutine
+	if _, err := url.Detach(); err != nil {
+		t.Fatalf("failed to detach URL: %v", err)
+	}
+	clone, err := url.Clone()
+	if err != nil {
+		t.Fatalf("failed to clone URL: %v", err)
+	}
+	clone.Free()
+}
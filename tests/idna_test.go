@@ -0,0 +1,132 @@
+package goadawasm_test
+
+import (
+	"errors"
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestToASCII(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"www.GOoglé.com", "www.xn--googl-fsa.com"},
+		{"example.com", "example.com"},
+		{"EXAMPLE.COM", "example.com"},
+		{"bücher.de", "xn--bcher-kva.de"},
+		{"日本語.jp", "xn--wgv71a119e.jp"},
+		{"xn--bcher-kva.de", "xn--bcher-kva.de"},
+		{"faß.de", "xn--fa-hia.de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := goadawasm.ToASCII(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			compareString(t, tt.expected, got, "Expected ASCII domain")
+		})
+	}
+}
+
+func TestToASCIIInvalid(t *testing.T) {
+	tests := []string{
+		"xn--a.com",
+		"xn--ls8h=",
+		"a‍b.com",
+		"exa mple.com",
+		"a<b.com",
+		"a%41.com",
+		"user@example.com",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := goadawasm.ToASCII(input)
+			if !errors.Is(err, goadawasm.ErrInvalidDomain) {
+				t.Errorf("expected ErrInvalidDomain, got %q (err = %v)", got, err)
+			}
+		})
+	}
+
+	if _, err := goadawasm.ToASCII(""); !errors.Is(err, goadawasm.ErrEmptyString) {
+		t.Errorf("expected ErrEmptyString for empty domain, got %v", err)
+	}
+}
+
+func TestToUnicode(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"www.xn--googl-fsa.com", "www.googlé.com"},
+		{"xn--bcher-kva.de", "bücher.de"},
+		{"xn--wgv71a119e.jp", "日本語.jp"},
+		{"example.com", "example.com"},
+		{"bücher.de", "bücher.de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := goadawasm.ToUnicode(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			compareString(t, tt.expected, got, "Expected Unicode domain")
+		})
+	}
+}
+
+func TestToUnicodeInvalid(t *testing.T) {
+	tests := []string{
+		"xn--a.com",
+		"xn--ls8h=",
+		"a<b.com",
+		"exa mple.com",
+		"a%41.com",
+		"user@example.com",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			got, err := goadawasm.ToUnicode(input)
+			if !errors.Is(err, goadawasm.ErrInvalidDomain) {
+				t.Errorf("expected ErrInvalidDomain, got %q (err = %v)", got, err)
+			}
+		})
+	}
+
+	if _, err := goadawasm.ToUnicode(""); !errors.Is(err, goadawasm.ErrEmptyString) {
+		t.Errorf("expected ErrEmptyString for empty domain, got %v", err)
+	}
+}
+
+func TestToASCIIMatchesHostname(t *testing.T) {
+	domains := []string{
+		"www.GOoglé.com",
+		"bücher.de",
+		"faß.de",
+		"ＥＸＡＭＰＬＥ.com",
+		"日本語.jp",
+		"ß.example",
+	}
+
+	for _, domain := range domains {
+		t.Run(domain, func(t *testing.T) {
+			url, err := goadawasm.New("https://" + domain + "/")
+			if err != nil {
+				t.Fatalf("failed to parse URL: %v", err)
+			}
+			defer url.Free()
+
+			ascii, err := goadawasm.ToASCII(domain)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			compareString(t, url.Hostname(), ascii, "Expected ToASCII to match URL hostname")
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package goadawasm_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestUrlsShareInstances(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithInstances(2))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	urls := make([]*goadawasm.Url, 0, 1000)
+	for i := 0; i < cap(urls); i++ {
+		url, err := engine.New("https://example.com/" + strconv.Itoa(i))
+		if err != nil {
+			t.Fatalf("failed to parse URL: %v", err)
+		}
+		urls = append(urls, url)
+	}
+
+	if n := engine.Instances(); n < 1 || n > 2 {
+		t.Errorf("expected at most 2 instances for %d live URLs, got %d", len(urls), n)
+	}
+	for i, url := range urls {
+		compareString(t, "/"+strconv.Itoa(i), url.Pathname(), "Expected pathname")
+		url.Free()
+	}
+}
+
+func TestUrlsConcurrentOnSharedInstance(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithInstances(1))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	original, err := engine.New("https://example.com/")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer original.Free()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Clones live in the same instance as the original
+			url, err := original.Clone()
+			if err != nil {
+				t.Errorf("failed to clone URL: %v", err)
+				return
+			}
+			defer url.Free()
+
+			for j := 0; j < 100; j++ {
+				path := "/" + strconv.Itoa(i) + "/" + strconv.Itoa(j)
+				url.SetPathname(path)
+				if url.Pathname() != path {
+					t.Errorf("expected pathname %q, got %q", path, url.Pathname())
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	compareString(t, "https://example.com/", original.Href(), "Expected original to be unchanged")
+	if n := engine.Instances(); n != 1 {
+		t.Errorf("expected 1 instance, got %d", n)
+	}
+}
+
+func TestEngineInstancesOption(t *testing.T) {
+	if _, err := goadawasm.NewEngine(goadawasm.WithInstances(0)); err == nil {
+		t.Error("expected an error for zero instances")
+	}
+}
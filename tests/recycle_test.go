@@ -0,0 +1,267 @@
+package goadawasm_test
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestRecycleOnMemoryLimit(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithInstances(1), goadawasm.WithMaxInstanceMemory(1<<20))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	live, err := engine.New("https://example.com/live")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+
+	// Growing the linear memory past the limit retires the instance
+	huge, err := engine.New("https://example.com/" + strings.Repeat("a", 1<<20))
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	if n := engine.Recycled(); n != 1 {
+		t.Fatalf("expected 1 recycled instance, got %d", n)
+	}
+
+	// The live URLs moved to a fresh instance, so the retired one is closed right away
+	if n := engine.Instances(); n != 1 {
+		t.Errorf("expected 1 open instance, got %d", n)
+	}
+	compareString(t, "https://example.com/live", live.Href(), "Expected URL moved out of the retired instance to work")
+	if err := live.Err(); err != nil {
+		t.Errorf("expected moved URL to be usable, got %v", err)
+	}
+	compareString(t, "/"+strings.Repeat("a", 1<<20), huge.Pathname(), "Expected huge URL to be moved intact")
+
+	// The instance holding the huge URL is not retired again for it
+	fresh, err := engine.New("https://example.com/fresh")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer fresh.Free()
+	compareString(t, "https://example.com/fresh", fresh.Href(), "Expected href")
+	if n := engine.Recycled(); n != 1 {
+		t.Errorf("expected 1 recycled instance, got %d", n)
+	}
+
+	live.Free()
+	huge.Free()
+	if n := engine.Instances(); n != 1 {
+		t.Errorf("expected 1 open instance, got %d", n)
+	}
+}
+
+func TestRecycleWithLongLivedUrls(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithInstances(1), goadawasm.WithMaxInstanceAllocations(1000))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	var kept []*goadawasm.Url
+	var params []*goadawasm.SearchParams
+	for round := 0; round < 10; round++ {
+		url, err := engine.New("https://example.com/kept/" + strconv.Itoa(round))
+		if err != nil {
+			t.Fatalf("failed to parse URL: %v", err)
+		}
+		kept = append(kept, url)
+		sp, err := engine.ParseSearchParams("round=" + strconv.Itoa(round))
+		if err != nil {
+			t.Fatalf("failed to parse search params: %v", err)
+		}
+		params = append(params, sp)
+
+		for i := 0; i < 1000; i++ {
+			url, err := engine.New("https://example.com/")
+			if err != nil {
+				t.Fatalf("failed to parse URL: %v", err)
+			}
+			// Getters allocate scratch memory, which must not count toward the limit
+			_ = url.Href()
+			url.Free()
+		}
+		if n := engine.Instances(); n != 1 {
+			t.Fatalf("round %d: expected 1 open instance, got %d", round, n)
+		}
+	}
+
+	if engine.Recycled() == 0 {
+		t.Error("expected instances to be recycled")
+	}
+	for i, url := range kept {
+		compareString(t, "https://example.com/kept/"+strconv.Itoa(i), url.Href(), "Expected kept URL to survive recycling")
+		url.Free()
+	}
+	for i, sp := range params {
+		compareString(t, "round="+strconv.Itoa(i), sp.String(), "Expected kept search params to survive recycling")
+		sp.Free()
+	}
+}
+
+func TestRecycleGettersDoNotCount(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithInstances(1), goadawasm.WithMaxInstanceAllocations(10))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	url, err := engine.New("https://example.com/")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	for i := 0; i < 1000; i++ {
+		_ = url.Href()
+		_ = url.Hostname()
+	}
+	if n := engine.Recycled(); n != 0 {
+		t.Errorf("expected no recycled instances, got %d", n)
+	}
+}
+
+func TestRecycleConcurrent(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithInstances(2), goadawasm.WithMaxInstanceAllocations(50))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			expected := "https://example.com/" + strconv.Itoa(g)
+			kept, err := engine.New(expected)
+			if err != nil {
+				t.Errorf("failed to parse URL: %v", err)
+				return
+			}
+			defer kept.Free()
+
+			for i := 0; i < 500; i++ {
+				url, err := engine.New("https://example.com/tmp")
+				if err != nil {
+					t.Errorf("failed to parse URL: %v", err)
+					return
+				}
+				url.Free()
+				if href := kept.Href(); href != expected {
+					t.Errorf("expected %s, got %s", expected, href)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := engine.Instances(); n > 2 {
+		t.Errorf("expected at most 2 open instances, got %d", n)
+	}
+}
+
+func TestRecycleOnAllocationLimit(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithInstances(1), goadawasm.WithMaxInstanceAllocations(100))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	for i := 0; i < 200; i++ {
+		url, err := engine.New("https://example.com/")
+		if err != nil {
+			t.Fatalf("failed to parse URL: %v", err)
+		}
+		compareString(t, "https://example.com/", url.Href(), "Expected href")
+		url.Free()
+	}
+
+	if engine.Recycled() == 0 {
+		t.Error("expected instances to be recycled")
+	}
+	if n := engine.Instances(); n != 1 {
+		t.Errorf("expected 1 open instance, got %d", n)
+	}
+}
+
+func TestRecycleInterruptible(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithMaxInstanceAllocations(10))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	for i := 0; i < 50; i++ {
+		url, err := engine.ParseContext(t.Context(), "https://example.com/")
+		if err != nil {
+			t.Fatalf("failed to parse URL: %v", err)
+		}
+		compareString(t, "https://example.com/", url.Href(), "Expected href")
+		url.Free()
+	}
+}
+
+func TestRecycleInterruptibleClones(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithMaxInstanceAllocations(10))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	url, err := engine.ParseContext(t.Context(), "https://example.com/")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	// The clones of a retired instance move together into a single one
+	var clones []*goadawasm.Url
+	for i := 0; i < 50; i++ {
+		clone, err := url.Clone()
+		if err != nil {
+			t.Fatalf("failed to clone URL: %v", err)
+		}
+		clones = append(clones, clone)
+	}
+	if engine.Recycled() == 0 {
+		t.Error("expected instances to be recycled")
+	}
+	if n := engine.Instances(); n > 2 {
+		t.Errorf("expected at most 2 open instances, got %d", n)
+	}
+	for _, clone := range clones {
+		compareString(t, "https://example.com/", clone.Href(), "Expected clone to survive recycling")
+		clone.Free()
+	}
+}
+
+func TestRecycleDisabled(t *testing.T) {
+	engine, err := goadawasm.NewEngine(
+		goadawasm.WithInstances(1),
+		goadawasm.WithMaxInstanceMemory(0),
+		goadawasm.WithMaxInstanceAllocations(0),
+	)
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	url, err := engine.New("https://example.com/" + strings.Repeat("a", 1<<20))
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	url.Free()
+
+	if n := engine.Recycled(); n != 0 {
+		t.Errorf("expected no recycled instances, got %d", n)
+	}
+}
@@ -0,0 +1,139 @@
+package goadawasm_test
+
+import (
+	"reflect"
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestSearchParamsParse(t *testing.T) {
+	params, err := goadawasm.ParseSearchParams("?a=1&b=2&a=3&c=&d")
+	if err != nil {
+		t.Fatalf("failed to parse search params: %v", err)
+	}
+	defer params.Free()
+
+	if params.Size() != 5 {
+		t.Errorf("expected size 5, got %d", params.Size())
+	}
+
+	if value, ok := params.Get("a"); !ok || value != "1" {
+		t.Errorf("Get(a): expected 1, got %q (found %v)", value, ok)
+	}
+	if value, ok := params.Get("c"); !ok || value != "" {
+		t.Errorf("Get(c): expected empty value, got %q (found %v)", value, ok)
+	}
+	if _, ok := params.Get("missing"); ok {
+		t.Error("Get(missing) should report no value")
+	}
+
+	if got := params.GetAll("a"); !reflect.DeepEqual(got, []string{"1", "3"}) {
+		t.Errorf("GetAll(a): expected [1 3], got %v", got)
+	}
+	if got := params.GetAll("missing"); len(got) != 0 {
+		t.Errorf("GetAll(missing): expected no values, got %v", got)
+	}
+
+	if !params.Has("d") {
+		t.Error("Has(d) should be true")
+	}
+	if params.Has("missing") {
+		t.Error("Has(missing) should be false")
+	}
+	if !params.HasValue("a", "3") {
+		t.Error("HasValue(a, 3) should be true")
+	}
+	if params.HasValue("a", "2") {
+		t.Error("HasValue(a, 2) should be false")
+	}
+
+	if got := params.Keys(); !reflect.DeepEqual(got, []string{"a", "b", "a", "c", "d"}) {
+		t.Errorf("Keys: got %v", got)
+	}
+	if got := params.Values(); !reflect.DeepEqual(got, []string{"1", "2", "3", "", ""}) {
+		t.Errorf("Values: got %v", got)
+	}
+	expectedEntries := []goadawasm.SearchParam{
+		{Key: "a", Value: "1"},
+		{Key: "b", Value: "2"},
+		{Key: "a", Value: "3"},
+		{Key: "c", Value: ""},
+		{Key: "d", Value: ""},
+	}
+	if got := params.Entries(); !reflect.DeepEqual(got, expectedEntries) {
+		t.Errorf("Entries: got %v", got)
+	}
+}
+
+func TestSearchParamsMutation(t *testing.T) {
+	params, err := goadawasm.ParseSearchParams("b=2&a=1&b=3")
+	if err != nil {
+		t.Fatalf("failed to parse search params: %v", err)
+	}
+	defer params.Free()
+
+	params.Append("c", "a b&c")
+	compareString(t, "b=2&a=1&b=3&c=a+b%26c", params.String(), "Expected appended pair")
+
+	params.Set("b", "4")
+	compareString(t, "b=4&a=1&c=a+b%26c", params.String(), "Expected set to collapse pairs")
+
+	params.Sort()
+	compareString(t, "a=1&b=4&c=a+b%26c", params.String(), "Expected sorted pairs")
+
+	params.Append("a", "2")
+	params.DeleteValue("a", "1")
+	compareString(t, "b=4&c=a+b%26c&a=2", params.String(), "Expected value-specific delete")
+
+	params.Delete("c")
+	compareString(t, "b=4&a=2", params.String(), "Expected delete")
+
+	params.Reset("?x=%F0%9F%98%80")
+	if value, _ := params.Get("x"); value != "😀" {
+		t.Errorf("expected percent-decoded value after reset, got %q", value)
+	}
+	if params.Size() != 1 {
+		t.Errorf("expected size 1 after reset, got %d", params.Size())
+	}
+}
+
+func TestUrlSearchParams(t *testing.T) {
+	url, err := goadawasm.New("https://example.com/path?q=go+lang&page=2#top")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	params, err := url.SearchParams()
+	if err != nil {
+		t.Fatalf("failed to get search params: %v", err)
+	}
+	defer params.Free()
+
+	if value, _ := params.Get("q"); value != "go lang" {
+		t.Errorf("expected q=go lang, got %q", value)
+	}
+
+	params.Set("page", "3")
+	params.Append("lang", "en")
+	url.SetSearchParams(params)
+	compareString(t, "https://example.com/path?q=go+lang&page=3&lang=en#top", url.Href(), "Expected updated search")
+
+	params.Reset("")
+	url.SetSearchParams(params)
+	compareString(t, "https://example.com/path#top", url.Href(), "Expected search to be removed")
+}
+
+func TestSearchParamsMemoryManagement(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		params, err := goadawasm.ParseSearchParams("a=1&b=2")
+		if err != nil {
+			t.Fatalf("failed to parse search params: %v", err)
+		}
+		if params.String() != "a=1&b=2" {
+			t.Errorf("unexpected serialization %q", params.String())
+		}
+		params.Free()
+	}
+}
@@ -0,0 +1,89 @@
+package goadawasm_test
+
+import (
+	"errors"
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestSetterErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		set    func(u *goadawasm.Url) error
+		reason string
+		target error
+		href   string
+	}{
+		{"href applied", "https://example.com/", func(u *goadawasm.Url) error { return u.SetHrefErr("https://other.com/x") }, "", nil, "https://other.com/x"},
+		{"href rejected", "https://example.com/", func(u *goadawasm.Url) error { return u.SetHrefErr("not a url") }, goadawasm.ReasonValueRejected, goadawasm.ErrValueRejected, "https://example.com/"},
+		{"host applied", "https://example.com/", func(u *goadawasm.Url) error { return u.SetHostErr("other.com:8080") }, "", nil, "https://other.com:8080/"},
+		{"host rejected", "https://example.com/", func(u *goadawasm.Url) error { return u.SetHostErr("exa mple.com") }, goadawasm.ReasonValueRejected, goadawasm.ErrValueRejected, "https://example.com/"},
+		{"host on opaque path", "mailto:user@example.com", func(u *goadawasm.Url) error { return u.SetHostErr("example.com") }, goadawasm.ReasonNotApplicable, goadawasm.ErrNotApplicable, "mailto:user@example.com"},
+		{"hostname on opaque path", "mailto:user@example.com", func(u *goadawasm.Url) error { return u.SetHostnameErr("example.com") }, goadawasm.ReasonNotApplicable, goadawasm.ErrNotApplicable, "mailto:user@example.com"},
+		{"protocol rejected", "https://example.com/", func(u *goadawasm.Url) error { return u.SetProtocolErr("foo") }, goadawasm.ReasonValueRejected, goadawasm.ErrValueRejected, "https://example.com/"},
+		{"protocol applied", "https://example.com/", func(u *goadawasm.Url) error { return u.SetProtocolErr("wss") }, "", nil, "wss://example.com/"},
+		{"protocol with tab applied", "http://a.com/", func(u *goadawasm.Url) error { return u.SetProtocolErr("ht\ttps") }, "", nil, "https://a.com/"},
+		{"protocol with newline applied", "https://a.com/", func(u *goadawasm.Url) error { return u.SetProtocolErr("ht\ntp:") }, "", nil, "http://a.com/"},
+		{"username applied", "https://example.com/", func(u *goadawasm.Url) error { return u.SetUsernameErr("user") }, "", nil, "https://user@example.com/"},
+		{"username on file", "file:///etc/hosts", func(u *goadawasm.Url) error { return u.SetUsernameErr("user") }, goadawasm.ReasonNotApplicable, goadawasm.ErrNotApplicable, "file:///etc/hosts"},
+		{"password without host", "sc:/path", func(u *goadawasm.Url) error { return u.SetPasswordErr("secret") }, goadawasm.ReasonNotApplicable, goadawasm.ErrNotApplicable, "sc:/path"},
+		{"port rejected", "https://example.com/", func(u *goadawasm.Url) error { return u.SetPortErr("99999") }, goadawasm.ReasonValueRejected, goadawasm.ErrValueRejected, "https://example.com/"},
+		{"port on file", "file:///etc/hosts", func(u *goadawasm.Url) error { return u.SetPortErr("8080") }, goadawasm.ReasonNotApplicable, goadawasm.ErrNotApplicable, "file:///etc/hosts"},
+		{"pathname applied", "https://example.com/", func(u *goadawasm.Url) error { return u.SetPathnameErr("/a b") }, "", nil, "https://example.com/a%20b"},
+		{"pathname on opaque path", "mailto:user@example.com", func(u *goadawasm.Url) error { return u.SetPathnameErr("/x") }, goadawasm.ReasonNotApplicable, goadawasm.ErrNotApplicable, "mailto:user@example.com"},
+		{"search applied", "mailto:user@example.com", func(u *goadawasm.Url) error { return u.SetSearchErr("subject=hi") }, "", nil, "mailto:user@example.com?subject=hi"},
+		{"hash applied", "https://example.com/", func(u *goadawasm.Url) error { return u.SetHashErr("top") }, "", nil, "https://example.com/#top"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, err := goadawasm.New(tt.url)
+			if err != nil {
+				t.Fatalf("failed to parse URL: %v", err)
+			}
+			defer url.Free()
+
+			err = tt.set(url)
+			if tt.target == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else {
+				var setterErr *goadawasm.SetterError
+				if !errors.As(err, &setterErr) {
+					t.Fatalf("expected a SetterError, got %v", err)
+				}
+				if !errors.Is(err, tt.target) {
+					t.Errorf("expected error to match %v, got %v", tt.target, err)
+				}
+				compareString(t, tt.reason, setterErr.Reason, "Expected reason")
+			}
+			compareString(t, tt.href, url.Href(), "Expected href")
+		})
+	}
+}
+
+func TestSetterErrorMessage(t *testing.T) {
+	url, err := goadawasm.New("https://example.com/")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	err = url.SetPortErr("http")
+	compareString(t, `set port "http": value rejected`, err.Error(), "Expected message")
+}
+
+func TestSyncUrlSetterErrors(t *testing.T) {
+	url, err := goadawasm.NewSync("mailto:user@example.com")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	if err := url.SetHostErr("example.com"); !errors.Is(err, goadawasm.ErrNotApplicable) {
+		t.Errorf("expected ErrNotApplicable, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package goadawasm_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+var (
+	_ sql.Scanner   = (*goadawasm.URLRecord)(nil)
+	_ driver.Valuer = goadawasm.URLRecord{}
+	_ sql.Scanner   = (*goadawasm.NullURLRecord)(nil)
+	_ driver.Valuer = goadawasm.NullURLRecord{}
+)
+
+func TestRecordScan(t *testing.T) {
+	for _, src := range []any{"HTTPS://Example.com:443/a/./b", []byte("HTTPS://Example.com:443/a/./b")} {
+		var record goadawasm.URLRecord
+		if err := record.Scan(src); err != nil {
+			t.Fatalf("failed to scan %v: %v", src, err)
+		}
+		compareString(t, "https://example.com/a/b", record.Href(), "Expected normalized href")
+
+		value, err := record.Value()
+		if err != nil {
+			t.Fatalf("failed to get value: %v", err)
+		}
+		if value != "https://example.com/a/b" {
+			t.Errorf("expected the normalized href as value, got %v", value)
+		}
+	}
+}
+
+func TestRecordScanInvalid(t *testing.T) {
+	var record goadawasm.URLRecord
+
+	err := record.Scan("not a url")
+	if !errors.Is(err, goadawasm.ErrInvalidUrl) {
+		t.Fatalf("expected ErrInvalidUrl, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `"not a url"`) {
+		t.Errorf("expected the error to name the value, got %v", err)
+	}
+	if !record.IsZero() {
+		t.Error("expected a failed scan to leave the record unchanged")
+	}
+
+	if err := record.Scan(""); !errors.Is(err, goadawasm.ErrEmptyString) {
+		t.Errorf("expected ErrEmptyString, got %v", err)
+	}
+	if err := record.Scan(nil); err == nil {
+		t.Error("expected an error for NULL")
+	}
+	if err := record.Scan(42); err == nil {
+		t.Error("expected an error for an integer")
+	}
+	if _, err := record.Value(); err == nil {
+		t.Error("expected an error storing the zero record")
+	}
+}
+
+func TestNullRecord(t *testing.T) {
+	var null goadawasm.NullURLRecord
+	if err := null.Scan(nil); err != nil || null.Valid {
+		t.Fatalf("expected NULL to scan as invalid, got %v", err)
+	}
+	if value, err := null.Value(); err != nil || value != nil {
+		t.Errorf("expected a nil value for NULL, got %v, %v", value, err)
+	}
+
+	if err := null.Scan("http://example.com:80/"); err != nil || !null.Valid {
+		t.Fatalf("failed to scan URL: %v", err)
+	}
+	if value, err := null.Value(); err != nil || value != "http://example.com/" {
+		t.Errorf("expected the normalized href, got %v, %v", value, err)
+	}
+
+	if err := null.Scan("https://exa mple.com"); !errors.Is(err, goadawasm.ErrInvalidUrl) || null.Valid {
+		t.Errorf("expected an invalid URL to fail, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package goadawasm_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestNormalizeStream(t *testing.T) {
+	input := "HTTPS://Example.com/a/./b\r\n" +
+		"not a url\n" +
+		"\n" +
+		"  http://example.com:80/  \n" +
+		"https://exa mple.com\n" +
+		"https://example.com/last"
+
+	type invalid struct {
+		line  int
+		input string
+	}
+	var invalids []invalid
+	var out bytes.Buffer
+	err := goadawasm.NormalizeStream(t.Context(), strings.NewReader(input), &out, &goadawasm.NormalizeOptions{
+		OnInvalid: func(line int, input string, err error) {
+			if !errors.Is(err, goadawasm.ErrInvalidUrl) {
+				t.Errorf("expected ErrInvalidUrl, got %v", err)
+			}
+			invalids = append(invalids, invalid{line, input})
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to normalize stream: %v", err)
+	}
+
+	compareString(t, "https://example.com/a/b\nhttp://example.com/\nhttps://example.com/last\n", out.String(), "Expected output")
+	expected := []invalid{{2, "not a url"}, {5, "https://exa mple.com"}}
+	if len(invalids) != len(expected) {
+		t.Fatalf("expected %v invalid lines, got %v", expected, invalids)
+	}
+	for i := range expected {
+		if invalids[i] != expected[i] {
+			t.Errorf("expected invalid line %v, got %v", expected[i], invalids[i])
+		}
+	}
+}
+
+func TestNormalizeStreamPreservesOrder(t *testing.T) {
+	engine, err := goadawasm.NewEngine(goadawasm.WithInstances(4))
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer engine.Close()
+
+	var input, expected strings.Builder
+	for i := 0; i < 10000; i++ {
+		input.WriteString("/" + strconv.Itoa(i) + "?Q\n")
+		expected.WriteString("https://example.com/" + strconv.Itoa(i) + "?Q\n")
+	}
+
+	var out bytes.Buffer
+	err = engine.NormalizeStream(t.Context(), strings.NewReader(input.String()), &out, &goadawasm.NormalizeOptions{
+		Workers: 8,
+		Base:    "https://EXAMPLE.com/",
+	})
+	if err != nil {
+		t.Fatalf("failed to normalize stream: %v", err)
+	}
+	if out.String() != expected.String() {
+		t.Error("expected normalized lines in input order")
+	}
+}
+
+func TestNormalizeStreamInvalidBase(t *testing.T) {
+	err := goadawasm.NormalizeStream(t.Context(), strings.NewReader("/a\n"), &bytes.Buffer{}, &goadawasm.NormalizeOptions{
+		Base: "not a base",
+	})
+	if !errors.Is(err, goadawasm.ErrInvalidUrl) {
+		t.Errorf("expected ErrInvalidUrl, got %v", err)
+	}
+}
+
+func TestNormalizeStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	input := strings.Repeat("https://example.com/\n", 10000)
+	err := goadawasm.NormalizeStream(ctx, strings.NewReader(input), &bytes.Buffer{}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestNormalizeStreamCanceledWhileReading(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("https://example.com/\n"))
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	var out bytes.Buffer
+	go func() {
+		done <- goadawasm.NormalizeStream(ctx, pr, &out, nil)
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected NormalizeStream to return while a read is in progress")
+	}
+
+	// The reader belongs to the caller, so it is left open and the read in progress still takes input
+	if _, err := pw.Write([]byte("https://example.com/\n")); err != nil {
+		t.Errorf("expected the reader to be left open, got %v", err)
+	}
+	pw.Close()
+}
+
+// blockingReader blocks every read until its channel is closed
+type blockingReader chan struct{}
+
+func (r blockingReader) Read(p []byte) (int, error) {
+	<-r
+	return 0, io.EOF
+}
+
+func TestNormalizeStreamCanceledWithoutCloser(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	r := make(blockingReader)
+	defer close(r)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- goadawasm.NormalizeStream(ctx, r, &bytes.Buffer{}, nil)
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected NormalizeStream to return while a read is in progress")
+	}
+}
+
+func TestNormalizeStreamEngineFailure(t *testing.T) {
+	engine := newFaultyEngine(t)
+
+	// Workers share the instance, so a failing line also discards the URLs of other workers
+	var input, expected strings.Builder
+	var failing []int
+	for i := 1; i <= 3000; i++ {
+		if i%500 == 3 {
+			input.WriteString(hugeURL() + "\n")
+			failing = append(failing, i)
+			continue
+		}
+		input.WriteString("https://example.com/" + strconv.Itoa(i) + "\n")
+		expected.WriteString("https://example.com/" + strconv.Itoa(i) + "\n")
+	}
+
+	var lines []int
+	var out bytes.Buffer
+	err := engine.NormalizeStream(t.Context(), strings.NewReader(input.String()), &out, &goadawasm.NormalizeOptions{
+		Workers: 4,
+		OnInvalid: func(line int, input string, err error) {
+			if !errors.Is(err, goadawasm.ErrEngineFailure) {
+				t.Errorf("expected ErrEngineFailure, got %v", err)
+			}
+			lines = append(lines, line)
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to normalize stream: %v", err)
+	}
+
+	if out.String() != expected.String() {
+		t.Error("expected every valid line in the output, and nothing else")
+	}
+	if len(lines) != len(failing) {
+		t.Fatalf("expected lines %v to fail, got %v", failing, lines)
+	}
+	for i := range failing {
+		if lines[i] != failing[i] {
+			t.Errorf("expected line %d to fail, got %d", failing[i], lines[i])
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestNormalizeStreamWriteError(t *testing.T) {
+	input := strings.Repeat("https://example.com/\n", 10000)
+	err := goadawasm.NormalizeStream(t.Context(), strings.NewReader(input), failingWriter{}, nil)
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("expected the write error, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package goadawasm_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestSyncUrlConcurrentAccess(t *testing.T) {
+	url, err := goadawasm.NewSync("https://example.com/path")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	clone, err := url.Clone()
+	if err != nil {
+		t.Fatalf("failed to clone URL: %v", err)
+	}
+	defer clone.Free()
+
+	const goroutines = 20
+	const iterations = 100
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			// Half of the goroutines use the clone, which shares the WASM instance
+			target := url
+			if g%2 == 1 {
+				target = clone
+			}
+			for i := 0; i < iterations; i++ {
+				target.SetHash(fmt.Sprintf("#g%d-%d", g, i))
+				if !target.SetPathname("/p" + strings.Repeat("x", i%7)) {
+					t.Errorf("SetPathname failed")
+					return
+				}
+				if href := target.Href(); !strings.HasPrefix(href, "https://example.com/p") {
+					t.Errorf("unexpected href %s", href)
+					return
+				}
+				if _, err := target.Components(); err != nil {
+					t.Errorf("Components failed: %v", err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestSyncUrlDo(t *testing.T) {
+	url, err := goadawasm.NewSyncWithBase("other", "https://example.com/base/")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Read-modify-write sequences are atomic inside Do
+			url.Do(func(u *goadawasm.Url) {
+				u.SetPathname(u.Pathname() + "x")
+			})
+		}()
+	}
+	wg.Wait()
+
+	compareString(t, "/base/otherxxxxxxxxxx", url.Pathname(), "Expected every update to be applied")
+}
+
+func TestUrlSync(t *testing.T) {
+	plain, err := goadawasm.New("https://example.com/")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	url := plain.Sync()
+	defer url.Free()
+
+	if ok := url.SetPort("8080"); !ok {
+		t.Error("SetPort should succeed")
+	}
+	record, err := url.Detach()
+	if err != nil {
+		t.Fatalf("failed to detach URL: %v", err)
+	}
+	compareString(t, "https://example.com:8080/", record.Href(), "Expected synced url state")
+}
@@ -0,0 +1,91 @@
+package goadawasm_test
+
+import (
+	"testing"
+
+	goadawasm "github.com/yzqzss/goada-wasm"
+)
+
+func TestHostType(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected goadawasm.HostType
+	}{
+		{"https://example.com/", goadawasm.HostDefault},
+		{"https://127.0.0.1/", goadawasm.HostIPv4},
+		{"https://0x7f.1/", goadawasm.HostIPv4},
+		{"https://[::1]:8080/", goadawasm.HostIPv6},
+		{"file:///home/user/file.txt", goadawasm.HostDefault},
+		{"mailto:user@example.com", goadawasm.HostDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			url, err := goadawasm.New(tt.url)
+			if err != nil {
+				t.Fatalf("failed to parse URL: %v", err)
+			}
+			defer url.Free()
+
+			if got := url.HostType(); got != tt.expected {
+				t.Errorf("expected host type %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSchemeType(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected goadawasm.SchemeType
+		special  bool
+	}{
+		{"http://example.com/", goadawasm.SchemeHTTP, true},
+		{"HTTPS://example.com/", goadawasm.SchemeHTTPS, true},
+		{"ws://example.com/", goadawasm.SchemeWS, true},
+		{"wss://example.com/", goadawasm.SchemeWSS, true},
+		{"ftp://example.com/", goadawasm.SchemeFTP, true},
+		{"file:///tmp/x", goadawasm.SchemeFile, true},
+		{"mailto:user@example.com", goadawasm.SchemeNotSpecial, false},
+		{"git+ssh://example.com/repo", goadawasm.SchemeNotSpecial, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			url, err := goadawasm.New(tt.url)
+			if err != nil {
+				t.Fatalf("failed to parse URL: %v", err)
+			}
+			defer url.Free()
+
+			got := url.SchemeType()
+			if got != tt.expected {
+				t.Errorf("expected scheme type %v, got %v", tt.expected, got)
+			}
+			if got.IsSpecial() != tt.special {
+				t.Errorf("expected IsSpecial %v, got %v", tt.special, got.IsSpecial())
+			}
+		})
+	}
+}
+
+func TestSchemeTypeUnknown(t *testing.T) {
+	unknown := goadawasm.SchemeType(42)
+	if unknown.IsSpecial() {
+		t.Error("a scheme type outside the enumeration should not be special")
+	}
+	compareString(t, "unknown", unknown.String(), "Expected name")
+}
+
+func TestSchemeTypeAfterSetProtocol(t *testing.T) {
+	url, err := goadawasm.New("http://example.com/")
+	if err != nil {
+		t.Fatalf("failed to parse URL: %v", err)
+	}
+	defer url.Free()
+
+	url.SetProtocol("wss:")
+	if got := url.SchemeType(); got != goadawasm.SchemeWSS {
+		t.Errorf("expected scheme type wss, got %v", got)
+	}
+}
@@ -68,6 +68,10 @@ func TestAdaWASM(t *testing.T) {
 					t.Errorf("Href() got = '%v', want '%v'", got.Href(), tt.Href)
 				}
 
+				if tt.Origin != "" && got.Origin() != tt.Origin {
+					t.Errorf("Origin() got = '%v', want '%v'", got.Origin(), tt.Origin)
+				}
+
 				if got.Protocol() != tt.Protocol {
 					t.Errorf("Scheme got = '%v', want '%v'", got.Protocol(), tt.Protocol)
 				}
@@ -116,3 +120,46 @@ func TestAdaWASM(t *testing.T) {
 		})
 	}
 }
+
+func TestComponentsMatchGetters(t *testing.T) {
+	var tests []args
+	json.Unmarshal(urlTestData, &tests)
+	empty := args{}
+
+	for _, tt := range tests {
+		if tt == empty || tt.Failure {
+			continue
+		}
+
+		var got *goadawasm.Url
+		var err error
+		if tt.Base != "" {
+			got, err = goadawasm.NewWithBase(tt.Input, tt.Base)
+		} else {
+			got, err = goadawasm.New(tt.Input)
+		}
+		if err != nil {
+			continue
+		}
+
+		expected := goadawasm.Components{
+			Href:     got.Href(),
+			Protocol: got.Protocol(),
+			Username: got.Username(),
+			Password: got.Password(),
+			Host:     got.Host(),
+			Hostname: got.Hostname(),
+			Port:     got.Port(),
+			Pathname: got.Pathname(),
+			Search:   got.Search(),
+			Hash:     got.Hash(),
+		}
+		components, err := got.Components()
+		if err != nil {
+			t.Errorf("Components() for %q error = '%v'", tt.Input, err)
+		} else if components != expected {
+			t.Errorf("Components() for %q got = '%+v', want '%+v'", tt.Input, components, expected)
+		}
+		got.Free()
+	}
+}
@@ -0,0 +1,90 @@
+package goadawasm
+
+// invalidUint8 is the HostType or SchemeType of a URL whose instance failed, which String reports as "unknown"
+const invalidUint8 = 0xff
+
+// HostType is the kind of host of a URL, as reported by ada_get_host_type
+type HostType uint8
+
+const (
+	HostDefault HostType = 0 // Domain, opaque or empty host
+	HostIPv4    HostType = 1
+	HostIPv6    HostType = 2
+)
+
+// String returns the name of the host type
+func (t HostType) String() string {
+	switch t {
+	case HostDefault:
+		return "default"
+	case HostIPv4:
+		return "ipv4"
+	case HostIPv6:
+		return "ipv6"
+	default:
+		return "unknown"
+	}
+}
+
+// SchemeType is the kind of scheme of a URL, as reported by ada_get_scheme_type
+type SchemeType uint8
+
+const (
+	SchemeHTTP       SchemeType = 0
+	SchemeNotSpecial SchemeType = 1
+	SchemeHTTPS      SchemeType = 2
+	SchemeWS         SchemeType = 3
+	SchemeFTP        SchemeType = 4
+	SchemeWSS        SchemeType = 5
+	SchemeFile       SchemeType = 6
+)
+
+// String returns the name of the scheme type
+func (t SchemeType) String() string {
+	switch t {
+	case SchemeHTTP:
+		return "http"
+	case SchemeNotSpecial:
+		return "not-special"
+	case SchemeHTTPS:
+		return "https"
+	case SchemeWS:
+		return "ws"
+	case SchemeFTP:
+		return "ftp"
+	case SchemeWSS:
+		return "wss"
+	case SchemeFile:
+		return "file"
+	default:
+		return "unknown"
+	}
+}
+
+// IsSpecial checks if the scheme is one of the WHATWG special schemes.
+// Values outside the enumeration are not special.
+func (t SchemeType) IsSpecial() bool {
+	switch t {
+	case SchemeHTTP, SchemeHTTPS, SchemeWS, SchemeFTP, SchemeWSS, SchemeFile:
+		return true
+	default:
+		return false
+	}
+}
+
+// HostType returns the kind of host of the URL.
+// A URL whose instance failed reports a type outside the enumeration; Url.Err tells why.
+func (u *Url) HostType() HostType {
+	u.lock()
+	defer u.unlock()
+	return HostType(u.parser.callAdaUint8Function("ada_get_host_type", u.cpointer))
+}
+
+// SchemeType returns the kind of scheme of the URL.
+// A URL whose instance failed reports a type outside the enumeration, which is neither SchemeHTTP
+// nor special; check Url.Err before relying on it.
+func (u *Url) SchemeType() SchemeType {
+	u.lock()
+	defer u.unlock()
+	return SchemeType(u.parser.callAdaUint8Function("ada_get_scheme_type", u.cpointer))
+}